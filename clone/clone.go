@@ -0,0 +1,102 @@
+package clone
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/definition"
+	"github.com/wedeploy/cli/projects"
+)
+
+// ErrProjectAlreadyExists is used when the directory already has a project
+var ErrProjectAlreadyExists = errors.New("There is already a project on the directory.")
+
+// Clone writes the definitions of a remote project and its containers
+// on a directory (project.json and one container.json per container)
+// and returns the IDs of the containers cloned
+func Clone(projectID, dir string) ([]string, error) {
+	var project, err = projects.Get(projectID)
+
+	if err != nil {
+		return nil, errwrap.Wrapf("Can't get project: {{err}}", err)
+	}
+
+	if err = tryDirectory(dir); err != nil {
+		return nil, err
+	}
+
+	var cs = project.Containers
+
+	project.Health = ""
+	project.Containers = nil
+
+	if err = writeJSON(filepath.Join(dir, "project.json"), project); err != nil {
+		return nil, errwrap.Wrapf("Can't write project.json: {{err}}", err)
+	}
+
+	var ids = []string{}
+
+	for id := range cs {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if err = writeContainer(dir, id, cs[id]); err != nil {
+			return nil, errwrap.Wrapf("Can't clone container "+id+": {{err}}", err)
+		}
+	}
+
+	return ids, nil
+}
+
+func tryDirectory(dir string) error {
+	if err := os.MkdirAll(dir, 0775); err != nil {
+		return errwrap.Wrapf("Can't create project directory: {{err}}", err)
+	}
+
+	var _, err = definition.Find(dir, "project")
+
+	switch {
+	case err == nil:
+		return ErrProjectAlreadyExists
+	case os.IsNotExist(err):
+		return nil
+	default:
+		return err
+	}
+}
+
+func writeContainer(dir, id string, c *containers.Container) error {
+	var cdir = filepath.Join(dir, id)
+
+	if c == nil {
+		c = &containers.Container{}
+	}
+
+	c.ID = id
+	c.Health = ""
+
+	if err := os.MkdirAll(cdir, 0775); err != nil {
+		return err
+	}
+
+	return writeJSON(filepath.Join(cdir, "container.json"), c)
+}
+
+func writeJSON(file string, data interface{}) error {
+	var bin, err = json.MarshalIndent(data, "", "    ")
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, bin, 0644)
+}
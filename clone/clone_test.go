@@ -0,0 +1,134 @@
+package clone
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wedeploy/cli/configmock"
+	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/projects"
+	"github.com/wedeploy/cli/servertest"
+	"github.com/wedeploy/cli/tdata"
+)
+
+func TestClone(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	var dir, err = ioutil.TempDir("", "we-clone")
+
+	if err != nil {
+		panic(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	servertest.Mux.HandleFunc("/projects/shop",
+		tdata.ServerJSONFileHandler("mocks/project_get_response.json"))
+
+	var path = filepath.Join(dir, "shop")
+	ids, err := Clone("shop", path)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if want := []string{"api", "db"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Wanted containers %v, got %v instead", want, ids)
+	}
+
+	p, err := projects.Read(path)
+
+	if err != nil {
+		t.Errorf("Expected no error reading project, got %v instead", err)
+	}
+
+	var wantProject = &projects.Project{
+		ID:           "shop",
+		Name:         "Shop",
+		CustomDomain: "shop.example.com",
+	}
+
+	if !reflect.DeepEqual(p, wantProject) {
+		t.Errorf("Wanted project %+v, got %+v instead", wantProject, p)
+	}
+
+	api, err := containers.Read(filepath.Join(path, "api"))
+
+	if err != nil {
+		t.Errorf("Expected no error reading container, got %v instead", err)
+	}
+
+	if api.ID != "api" || api.Health != "" || api.Instances != 3 ||
+		api.Env["DB"] != "db" || api.Hooks == nil || api.Hooks.Build != "npm install" {
+		t.Errorf("Unexpected container %+v", api)
+	}
+
+	if _, err = containers.Read(filepath.Join(path, "db")); err != nil {
+		t.Errorf("Expected no error reading container, got %v instead", err)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestCloneProjectAlreadyExists(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	var dir, err = ioutil.TempDir("", "we-clone")
+
+	if err != nil {
+		panic(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	tdata.ToFile(filepath.Join(dir, "project.json"), `{"id": "other"}`)
+
+	servertest.Mux.HandleFunc("/projects/shop",
+		tdata.ServerJSONFileHandler("mocks/project_get_response.json"))
+
+	if _, err = Clone("shop", dir); err != ErrProjectAlreadyExists {
+		t.Errorf("Expected error to be %v, got %v instead", ErrProjectAlreadyExists, err)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestCloneProjectNotFound(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	var dir, err = ioutil.TempDir("", "we-clone")
+
+	if err != nil {
+		panic(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	servertest.Mux.HandleFunc("/projects/shop",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			w.WriteHeader(404)
+			fmt.Fprintf(w, `{"code": 404, "message": "Not Found"}`)
+		})
+
+	if _, err = Clone("shop", filepath.Join(dir, "shop")); err == nil {
+		t.Errorf("Expected error, got nil instead")
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, "shop")); !os.IsNotExist(err) {
+		t.Errorf("Expected project directory not to be created, got %v instead", err)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
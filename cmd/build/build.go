@@ -65,7 +65,7 @@ func buildRun(cmd *cobra.Command, args []string) error {
 }
 
 func buildContainer(path string) error {
-	var container, err = containers.Read(path)
+	var container, err = containers.ReadRaw(path)
 
 	if err != nil {
 		return err
@@ -83,7 +83,7 @@ func buildContainer(path string) error {
 
 func checkProjectOrContainer(args []string) error {
 	var _, _, err = cmdcontext.GetProjectOrContainerID(args)
-	var _, errc = containers.Read(".")
+	var _, errc = containers.ReadRaw(".")
 
 	if err != nil && os.IsNotExist(errc) {
 		return errors.New("fatal: not a project or container")
@@ -0,0 +1,61 @@
+package cmdclone
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/clone"
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/createctx"
+)
+
+// CloneCmd writes the definitions of a remote project on a local directory
+var CloneCmd = &cobra.Command{
+	Use:   "clone <project> [directory]",
+	Short: "Clone a project definitions from a remote",
+	Long: `Use "we clone" to bootstrap a local project directory
+from a project running on a remote. It writes the project.json and
+one container.json per container (with its environment variables,
+hooks and number of instances).`,
+	RunE: cloneRun,
+	Example: `we clone shop
+we clone shop shop-staging --remote staging`,
+}
+
+func cloneRun(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New("Invalid number of arguments.")
+	}
+
+	if config.Context.Scope != "global" {
+		return createctx.ErrProjectPath
+	}
+
+	var projectID = args[0]
+	var dir = projectID
+
+	if len(args) == 2 {
+		dir = args[1]
+	}
+
+	var ids, err = clone.Clone(projectID, dir)
+
+	if err != nil {
+		return err
+	}
+
+	abs, err := filepath.Abs(dir)
+
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		fmt.Printf("Container %v cloned.\n", id)
+	}
+
+	fmt.Printf("Project %v cloned at %v\n", projectID, abs)
+	return nil
+}
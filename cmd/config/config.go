@@ -0,0 +1,114 @@
+package cmdconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/definition"
+	"github.com/wedeploy/cli/overlay"
+)
+
+// ConfigCmd is used for inspecting the project and containers definitions
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the project and containers definitions",
+	Long: `Definitions might have overlay files for profiles
+(i.e., container.staging.json merged over container.json)
+used with --profile or the profile associated with the remote.`,
+}
+
+var renderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render the definitions with the overlays of the active profile",
+	RunE:  renderRun,
+	Example: `we config render --profile staging
+we config render --remote staging`,
+}
+
+func init() {
+	ConfigCmd.AddCommand(renderCmd)
+}
+
+func renderRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return errors.New("Invalid number of arguments.")
+	}
+
+	if config.Context.Scope == "global" {
+		return errors.New("No project found. Run it inside a project.")
+	}
+
+	var profile = overlay.Profile()
+
+	if profile == "" {
+		fmt.Println("# No profile is active.")
+	} else {
+		fmt.Printf("# Profile %v\n", profile)
+	}
+
+	var root = config.Context.ProjectRoot
+
+	if err := render(root, "", "project", profile); err != nil {
+		return err
+	}
+
+	var dirs, err = getContainersDirectories()
+
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		if err := render(root, dir, "container", profile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getContainersDirectories() ([]string, error) {
+	if config.Context.ContainerRoot != "" {
+		var _, dir = filepath.Split(config.Context.ContainerRoot)
+		return []string{dir}, nil
+	}
+
+	return containers.GetListFromDirectory(config.Context.ProjectRoot)
+}
+
+// render a definition of a directory relative to the project root
+func render(root, dir, name, profile string) error {
+	var file, err = definition.Find(filepath.Join(root, dir), name)
+
+	if err != nil {
+		return err
+	}
+
+	content, err := overlay.Read(filepath.Join(root, dir), name, profile)
+
+	if err != nil {
+		return err
+	}
+
+	file, err = filepath.Rel(root, file)
+
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+
+	if err = json.Indent(&b, content, "", "    "); err != nil {
+		return errwrap.Wrapf("Can't render "+file+": {{err}}", err)
+	}
+
+	fmt.Printf("\n# %v\n%v\n", file, b.String())
+	return nil
+}
@@ -0,0 +1,180 @@
+package cmdconvert
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/definition"
+	"github.com/wedeploy/cli/overlay"
+)
+
+// ConvertCmd converts the definition files between JSON and YAML
+var ConvertCmd = &cobra.Command{
+	Use:   "convert --to yaml|json",
+	Short: "Convert the definition files between JSON and YAML",
+	Long: `Use "we convert" to migrate the project, auth and container
+definition files (and their profile overlays) of the current project
+or container to JSON or YAML. Comments of YAML files are not kept.`,
+	RunE: convertRun,
+	Example: `we convert --to yaml
+we convert --to json`,
+}
+
+var to string
+
+func init() {
+	ConvertCmd.Flags().StringVar(&to, "to", "", "Format to convert to (yaml or json)")
+}
+
+// definitionsDir is a directory with the names of the definitions it might have
+type definitionsDir struct {
+	path  string
+	names []string
+}
+
+func convertRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return errors.New("Invalid number of arguments.")
+	}
+
+	var from, err = getSourceFormat(to)
+
+	if err != nil {
+		return err
+	}
+
+	if config.Context.Scope == "global" {
+		return errors.New("No project found. Run it inside a project or container.")
+	}
+
+	dirs, err := getDefinitionsDirs()
+
+	if err != nil {
+		return err
+	}
+
+	var converted = 0
+
+	for _, dir := range dirs {
+		var c, err = convertDir(dir, from)
+		converted += c
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if converted == 0 {
+		fmt.Printf("No %v definition files to convert.\n", from)
+	}
+
+	return nil
+}
+
+func getSourceFormat(format string) (string, error) {
+	switch format {
+	case definition.YAML:
+		return definition.JSON, nil
+	case definition.JSON:
+		return definition.YAML, nil
+	case "":
+		return "", errors.New("Missing format to convert to: use --to yaml or --to json.")
+	default:
+		return "", fmt.Errorf("Invalid format %v: use yaml or json.", format)
+	}
+}
+
+func getDefinitionsDirs() ([]definitionsDir, error) {
+	if config.Context.Scope == "container" {
+		return []definitionsDir{
+			{config.Context.ContainerRoot, []string{"container"}},
+		}, nil
+	}
+
+	var root = config.Context.ProjectRoot
+	var dirs = []definitionsDir{
+		{root, []string{"project", "auth"}},
+	}
+
+	var list, err = containers.GetListFromDirectory(root)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range list {
+		dirs = append(dirs, definitionsDir{
+			filepath.Join(root, dir),
+			[]string{"container"},
+		})
+	}
+
+	return dirs, nil
+}
+
+func convertDir(dir definitionsDir, from string) (converted int, err error) {
+	for _, name := range dir.names {
+		var files, err = getDefinitionFiles(dir.path, name, from)
+
+		if err != nil {
+			return converted, err
+		}
+
+		for _, file := range files {
+			c, err := definition.Convert(file, to)
+
+			if err != nil {
+				return converted, err
+			}
+
+			converted++
+			fmt.Printf("Converted %v to %v\n", getRelativePath(file), filepath.Base(c))
+		}
+	}
+
+	return converted, nil
+}
+
+// getDefinitionFiles gets the files of a definition and its profile overlays
+// (i.e., container.json and container.staging.json)
+func getDefinitionFiles(dir, name, format string) ([]string, error) {
+	var files = []string{}
+	var file = filepath.Join(dir, name+"."+format)
+
+	if _, err := os.Stat(file); err == nil {
+		files = append(files, file)
+	}
+
+	var overlays, err = filepath.Glob(filepath.Join(dir, name+".*."+format))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range overlays {
+		var profile = strings.TrimSuffix(
+			strings.TrimPrefix(filepath.Base(o), name+"."), "."+format)
+
+		if overlay.IsValidProfile(profile) {
+			files = append(files, o)
+		}
+	}
+
+	return files, nil
+}
+
+func getRelativePath(file string) string {
+	var rel, err = filepath.Rel(config.Context.ProjectRoot, file)
+
+	if err != nil {
+		return file
+	}
+
+	return rel
+}
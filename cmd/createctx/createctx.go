@@ -2,8 +2,10 @@ package cmdcreate
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/spf13/cobra"
@@ -19,20 +21,108 @@ var CreateCmd = &cobra.Command{
 You can create a project anywhere on your machine.
 Containers can only be created from inside projects and
 are stored on the first subdirectory of its project.`,
-	RunE:    createRun,
-	Example: `we create relay`,
+	RunE: createRun,
+	Example: `we create relay
+we create relay --name Relay --custom-domain relay.example.com
+we create email --type wedeploy/email --env FROM=noreply@example.com
+we create email --template https://github.com/example/email-template.git
+we create --answers answers.json`,
 }
 
-func createRun(cmd *cobra.Command, args []string) error {
+var (
+	flagsAnswers createctx.Answers
+	env          []string
+	answersFile  string
+)
+
+func init() {
+	CreateCmd.Flags().StringVar(
+		&flagsAnswers.Type,
+		"type",
+		"",
+		"Container type (from the registry).")
+
+	CreateCmd.Flags().StringVar(
+		&flagsAnswers.Name,
+		"name",
+		"",
+		"Project or container name.")
+
+	CreateCmd.Flags().StringVar(
+		&flagsAnswers.CustomDomain,
+		"custom-domain",
+		"",
+		"Project custom domain.")
+
+	CreateCmd.Flags().StringArrayVarP(
+		&env,
+		"env",
+		"e",
+		nil,
+		"Container environment variables (KEY=VAL).")
+
+	CreateCmd.Flags().StringVar(
+		&flagsAnswers.Template,
+		"template",
+		"",
+		"Container template name, directory or git repository URL.")
+
+	CreateCmd.Flags().BoolVar(
+		&flagsAnswers.Offline,
+		"offline",
+		false,
+		"Use the cached container types registry only.")
+
+	CreateCmd.Flags().StringVar(
+		&answersFile,
+		"answers",
+		"",
+		"JSON file with the answers for creating without prompting.")
+}
+
+func getAnswers() (createctx.Answers, error) {
+	var answers createctx.Answers
 	var err error
 
+	if answersFile != "" {
+		if answers, err = createctx.ReadAnswers(answersFile); err != nil {
+			return answers, err
+		}
+	}
+
+	var fa = flagsAnswers
+
+	for _, e := range env {
+		var kv = strings.SplitN(e, "=", 2)
+
+		if len(kv) != 2 || kv[0] == "" {
+			return answers, fmt.Errorf("Invalid environment variable %v: use KEY=VAL.", e)
+		}
+
+		if fa.Env == nil {
+			fa.Env = map[string]string{}
+		}
+
+		fa.Env[kv[0]] = kv[1]
+	}
+
+	return answers.Merge(fa), nil
+}
+
+func createRun(cmd *cobra.Command, args []string) error {
+	var answers, err = getAnswers()
+
+	if err != nil {
+		return err
+	}
+
 	switch len(args) {
 	case 0:
-		err = createctx.New("", "")
+		err = createctx.New("", "", answers)
 	case 1:
-		err = createctx.New(args[0], "")
+		err = createctx.New(args[0], "", answers)
 	case 2:
-		err = cwdContextAndCreate(args[0], args[1])
+		err = cwdContextAndCreate(args[0], args[1], answers)
 	default:
 		err = errors.New("Invalid number of arguments.")
 	}
@@ -40,7 +130,7 @@ func createRun(cmd *cobra.Command, args []string) error {
 	return err
 }
 
-func cwdContextAndCreate(id, directory string) error {
+func cwdContextAndCreate(id, directory string, answers createctx.Answers) error {
 	var workingDir, err = os.Getwd()
 
 	if err != nil {
@@ -67,7 +157,7 @@ func cwdContextAndCreate(id, directory string) error {
 		return errwrap.Wrapf("Can't reset config object: {{err}}", err)
 	}
 
-	var cerr = createctx.New(id, abs)
+	var cerr = createctx.New(id, abs, answers)
 
 	if err = os.Chdir(workingDir); err != nil {
 		panic(err)
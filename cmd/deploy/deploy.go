@@ -0,0 +1,118 @@
+package cmddeploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/cmdcontext"
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/deploy"
+	"github.com/wedeploy/cli/projects"
+)
+
+// DeployCmd deploys the current project or container
+var DeployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Deploy the current project or container",
+	Long: `Deploy the current project or container
+
+The containers directories are uploaded as compressed archives.
+Files matching the patterns on a container .weignore file are not uploaded.`,
+	RunE: deployRun,
+	Example: `we deploy
+we deploy --remote staging`,
+}
+
+var quiet bool
+
+func init() {
+	DeployCmd.Flags().BoolVarP(
+		&quiet,
+		"quiet",
+		"q",
+		false,
+		"Deploy without watching status.")
+}
+
+func getContainersDirectoriesFromScope() ([]string, error) {
+	if config.Context.ContainerRoot != "" {
+		_, container := filepath.Split(config.Context.ContainerRoot)
+		return []string{container}, nil
+	}
+
+	var list, err = containers.GetListFromDirectory(config.Context.ProjectRoot)
+
+	if err != nil {
+		err = errwrap.Wrapf("Error retrieving containers list from directory: {{err}}", err)
+	}
+
+	return list, err
+}
+
+func deployRun(cmd *cobra.Command, args []string) error {
+	if _, _, err := cmdcontext.GetProjectOrContainerID(args); err != nil {
+		return err
+	}
+
+	var csDirs, err = getContainersDirectoriesFromScope()
+
+	if err != nil {
+		return err
+	}
+
+	project, err := projects.Read(config.Context.ProjectRoot)
+
+	if err != nil {
+		return err
+	}
+
+	created, err := projects.ValidateOrCreate(
+		filepath.Join(config.Context.ProjectRoot, "project.json"))
+
+	if err != nil {
+		return err
+	}
+
+	if created {
+		fmt.Printf("New project %v created.\n", project.ID)
+	}
+
+	var deployed = []string{}
+	var failed = 0
+
+	for _, dir := range csDirs {
+		var id, err = deployContainer(project.ID, filepath.Join(config.Context.ProjectRoot, dir))
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v/ dir error: %v\n", dir, err)
+			failed++
+			continue
+		}
+
+		deployed = append(deployed, id)
+	}
+
+	if !quiet && len(deployed) != 0 {
+		deploy.Watch(project.ID, deployed)
+	}
+
+	if failed != 0 {
+		return fmt.Errorf("Deploy failed for %d of %d containers.", failed, len(csDirs))
+	}
+
+	return nil
+}
+
+func deployContainer(projectID, containerPath string) (string, error) {
+	var d, err = deploy.New(projectID, containerPath)
+
+	if err != nil {
+		return "", err
+	}
+
+	return d.Container.ID, d.Run()
+}
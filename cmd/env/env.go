@@ -0,0 +1,119 @@
+package cmdenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/containers"
+)
+
+// EnvCmd prints the resolved environment variables of a container
+// and manages the environment variables of linked containers
+var EnvCmd = &cobra.Command{
+	Use:   "env [container]",
+	Short: "Print and manage the environment variables of a container",
+	Long: `Use "we env" to print the environment variables of a container
+after interpolating the ${VAR} and ${VAR:-default} references
+with the shell environment and the .env file next to its container.json.
+Use its subcommands to manage the environment variables of linked containers.
+Values of variables that look like secrets are masked unless --show-values is used.`,
+	RunE: envRun,
+	Example: `we env
+we env email
+we env list email
+we env set email FROM=noreply@example.com --save`,
+}
+
+var showValues bool
+
+func init() {
+	EnvCmd.PersistentFlags().BoolVar(
+		&showValues,
+		"show-values",
+		false,
+		"Show the values of variables that look like secrets.")
+}
+
+func envRun(cmd *cobra.Command, args []string) error {
+	var path, err = getContainerPath(args)
+
+	if err != nil {
+		return err
+	}
+
+	c, err := containers.Read(path)
+
+	if err != nil {
+		return err
+	}
+
+	printEnv(c)
+	return nil
+}
+
+func getContainerPath(args []string) (string, error) {
+	switch {
+	case len(args) > 1:
+		return "", errors.New("Invalid number of arguments.")
+	case len(args) == 0 && config.Context.ContainerRoot != "":
+		return config.Context.ContainerRoot, nil
+	case len(args) == 0:
+		return "", errors.New("No container found. Run it inside a container or pass its ID.")
+	case config.Context.ProjectRoot == "":
+		return "", errors.New("No project found. Run it inside a project.")
+	}
+
+	var dirs, err = containers.GetListFromDirectory(config.Context.ProjectRoot)
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, dir := range dirs {
+		var path = filepath.Join(config.Context.ProjectRoot, dir)
+		var c, err = containers.ReadRaw(path)
+
+		if err == nil && c.ID == args[0] {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("Container %v not found on project.", args[0])
+}
+
+func printEnv(c *containers.Container) {
+	printVars(c.Env)
+
+	if len(c.Unresolved) != 0 {
+		fmt.Fprintf(os.Stderr, "Warning: unresolved references on container %v: %v\n",
+			c.ID, strings.Join(c.Unresolved, ", "))
+	}
+}
+
+func printVars(env map[string]string) {
+	var keys = []string{}
+
+	for k := range env {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Printf("%v=%v\n", k, maskValue(k, env[k]))
+	}
+}
+
+func maskValue(key, value string) string {
+	if showValues || value == "" || !containers.LooksSecret(key) {
+		return value
+	}
+
+	return "********"
+}
@@ -0,0 +1,252 @@
+package cmdenv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/cmdcontext"
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/containers"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list [[project] container]",
+	Short: "List the environment variables of a linked container",
+	RunE:  listRun,
+	Example: `we env list
+we env list email
+we env list shop email`,
+}
+
+var getCmd = &cobra.Command{
+	Use:   "get [[project] container] KEY",
+	Short: "Get an environment variable of a linked container",
+	RunE:  getRun,
+	Example: `we env get email FROM
+we env get shop email SMTP_PASSWORD --show-values`,
+}
+
+var setCmd = &cobra.Command{
+	Use:   "set [[project] container] KEY=VALUE...",
+	Short: "Set environment variables of a linked container",
+	RunE:  setRun,
+	Example: `we env set email FROM=noreply@example.com
+we env set shop email FROM=noreply@example.com PORT=25 --save`,
+}
+
+var unsetCmd = &cobra.Command{
+	Use:   "unset [[project] container] KEY",
+	Short: "Remove an environment variable of a linked container",
+	RunE:  unsetRun,
+	Example: `we env unset email FROM
+we env unset shop email FROM --save`,
+}
+
+var save bool
+
+func init() {
+	for _, c := range []*cobra.Command{setCmd, unsetCmd} {
+		c.Flags().BoolVar(
+			&save,
+			"save",
+			false,
+			"Save the change on the container.json of the container.")
+	}
+
+	EnvCmd.AddCommand(listCmd)
+	EnvCmd.AddCommand(getCmd)
+	EnvCmd.AddCommand(setCmd)
+	EnvCmd.AddCommand(unsetCmd)
+}
+
+// getContainer gets the project and container IDs from the arguments
+// ([project] container) or the context
+func getContainer(args []string) (projectID, containerID string, err error) {
+	switch len(args) {
+	case 0:
+		return cmdcontext.GetProjectAndContainerID(args)
+	case 1:
+		projectID, err = cmdcontext.GetProjectID([]string{})
+		return projectID, args[0], err
+	case 2:
+		return args[0], args[1], nil
+	default:
+		return "", "", errors.New("Invalid number of arguments.")
+	}
+}
+
+func listRun(cmd *cobra.Command, args []string) error {
+	var projectID, containerID, err = getContainer(args)
+
+	if err != nil {
+		return err
+	}
+
+	env, err := containers.GetEnv(projectID, containerID)
+
+	if err != nil {
+		return err
+	}
+
+	printVars(env)
+	return nil
+}
+
+func getRun(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("Missing environment variable name.")
+	}
+
+	var key = args[len(args)-1]
+	var projectID, containerID, err = getContainer(args[:len(args)-1])
+
+	if err == nil && save {
+		err = checkLocalProject(projectID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	env, err := containers.GetEnv(projectID, containerID)
+
+	if err != nil {
+		return err
+	}
+
+	var value, ok = env[key]
+
+	if !ok {
+		return fmt.Errorf("Environment variable %v not found on container %v.", key, containerID)
+	}
+
+	fmt.Println(maskValue(key, value))
+	return nil
+}
+
+func setRun(cmd *cobra.Command, args []string) error {
+	var n = len(args)
+
+	for n > 0 && strings.Contains(args[n-1], "=") {
+		n--
+	}
+
+	if n == len(args) {
+		return errors.New("Missing environment variables: use KEY=VALUE.")
+	}
+
+	var projectID, containerID, err = getContainer(args[:n])
+
+	if err == nil && save {
+		err = checkLocalProject(projectID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, a := range args[n:] {
+		var kv = strings.SplitN(a, "=", 2)
+
+		if kv[0] == "" {
+			return fmt.Errorf("Invalid environment variable %v: use KEY=VALUE.", a)
+		}
+
+		if err = containers.SetEnv(projectID, containerID, kv[0], kv[1]); err != nil {
+			return errwrap.Wrapf("Can't set "+kv[0]+": {{err}}", err)
+		}
+
+		if save {
+			err = saveLocal(projectID, containerID, func(path string) error {
+				return containers.SaveEnv(path, kv[0], kv[1])
+			})
+		}
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Environment variable %v set on container %v.\n", kv[0], containerID)
+	}
+
+	return nil
+}
+
+func unsetRun(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("Missing environment variable name.")
+	}
+
+	var key = args[len(args)-1]
+	var projectID, containerID, err = getContainer(args[:len(args)-1])
+
+	if err == nil && save {
+		err = checkLocalProject(projectID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = containers.UnsetEnv(projectID, containerID, key); err != nil {
+		return errwrap.Wrapf("Can't unset "+key+": {{err}}", err)
+	}
+
+	if save {
+		err = saveLocal(projectID, containerID, func(path string) error {
+			return containers.RemoveEnv(path, key)
+		})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Environment variable %v removed from container %v.\n", key, containerID)
+	return nil
+}
+
+// checkLocalProject checks if changes to a project can be saved on the current project
+func checkLocalProject(projectID string) error {
+	if config.Context.ProjectRoot == "" {
+		return errors.New("Can't save the change locally: no project found. Run it inside a project.")
+	}
+
+	var local, err = cmdcontext.GetProjectID([]string{})
+
+	if err != nil {
+		return errwrap.Wrapf("Can't save the change locally: {{err}}", err)
+	}
+
+	if local != projectID {
+		return fmt.Errorf("Can't save the change locally: project %v is not the project on this directory (%v).",
+			projectID, local)
+	}
+
+	return nil
+}
+
+// saveLocal syncs a change back to the container.json (or container.yaml) of the container
+func saveLocal(projectID, containerID string, update func(path string) error) error {
+	var err = checkLocalProject(projectID)
+
+	if err != nil {
+		return err
+	}
+
+	var path string
+	path, err = getContainerPath([]string{containerID})
+
+	if err == nil {
+		err = update(path)
+	}
+
+	if err != nil {
+		return errwrap.Wrapf("Can't save the change locally: {{err}}", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package cmdimage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/run"
+)
+
+// ImageCmd manages the WeDeploy infrastructure docker image
+var ImageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Manage the WeDeploy infrastructure docker image",
+	Long: `Use "we image" to manage the WeDeploy infrastructure docker image
+without a registry, i.e., on air-gapped machines.`,
+	Example: `we image save wedeploy-local.tar
+we image load wedeploy-local.tar
+we image ls
+we image prune`,
+}
+
+var saveCmd = &cobra.Command{
+	Use:     "save",
+	Short:   "Save the infrastructure image to a tar archive",
+	Example: "we image save wedeploy-local.tar",
+	RunE:    saveRun,
+}
+
+var loadCmd = &cobra.Command{
+	Use:     "load",
+	Short:   "Load the infrastructure image from a tar archive",
+	Example: "we image load wedeploy-local.tar",
+	RunE:    loadRun,
+}
+
+var lsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the infrastructure images available locally",
+	RunE:  lsRun,
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove the infrastructure images not used by this version",
+	RunE:  pruneRun,
+}
+
+func saveRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("This command takes 1 argument.")
+	}
+
+	return run.SaveImage(args[0])
+}
+
+func loadRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("This command takes 1 argument.")
+	}
+
+	return run.LoadImage(args[0])
+}
+
+func lsRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return errors.New("This command doesn't take arguments.")
+	}
+
+	var list, err = run.ListImages()
+
+	if err != nil {
+		return err
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No WeDeploy infrastructure images found.")
+		return nil
+	}
+
+	for _, i := range list {
+		var mark = " "
+
+		if i.Current {
+			mark = "*"
+		}
+
+		fmt.Printf("%v %v\t%v\n", mark, i.Name, i.ID)
+	}
+
+	return nil
+}
+
+func pruneRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return errors.New("This command doesn't take arguments.")
+	}
+
+	return run.PruneImages()
+}
+
+func init() {
+	ImageCmd.AddCommand(saveCmd)
+	ImageCmd.AddCommand(loadCmd)
+	ImageCmd.AddCommand(lsCmd)
+	ImageCmd.AddCommand(pruneCmd)
+}
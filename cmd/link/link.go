@@ -1,9 +1,13 @@
 package cmdlink
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/spf13/cobra"
@@ -11,6 +15,11 @@ import (
 	"github.com/wedeploy/cli/config"
 	"github.com/wedeploy/cli/containers"
 	"github.com/wedeploy/cli/link"
+	"github.com/wedeploy/cli/prompt"
+	"github.com/wedeploy/cli/run"
+	"github.com/wedeploy/cli/schema"
+	"github.com/wedeploy/cli/workerpool"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 // LinkCmd links the given project or container locally
@@ -19,11 +28,29 @@ var LinkCmd = &cobra.Command{
 	Short: "Links the given project or container locally",
 	RunE:  linkRun,
 	Example: `we link
-we link <project>
-we link <container>`,
+we link <container> [<container>...]
+we link --tag backend --exclude "*-worker"
+we link --dry-run
+we link --parallel 2 --timeout 5m
+we link --prune
+we link --env staging
+we link --watch-files --ignore build --ignore "*.log"`,
 }
 
-var quiet bool
+var (
+	quiet      bool
+	dryRun     bool
+	tags       []string
+	exclude    []string
+	watchFiles bool
+	ignore     []string
+	debounce   time.Duration
+	parallel   int
+	timeout    time.Duration
+	prune      bool
+	yes        bool
+	env        string
+)
 
 func init() {
 	LinkCmd.Flags().BoolVarP(
@@ -32,10 +59,77 @@ func init() {
 		"q",
 		false,
 		"Link without watching status.")
+
+	LinkCmd.Flags().StringSliceVar(
+		&tags,
+		"tag",
+		nil,
+		"Link only containers with any of the given tags.")
+
+	LinkCmd.Flags().StringSliceVar(
+		&exclude,
+		"exclude",
+		nil,
+		"Don't link containers matching the given ID or directory patterns.")
+
+	LinkCmd.Flags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"Show what linking would change without linking.")
+
+	LinkCmd.Flags().BoolVar(
+		&watchFiles,
+		"watch-files",
+		false,
+		"Build and link containers again when their files change.")
+
+	LinkCmd.Flags().StringSliceVar(
+		&ignore,
+		"ignore",
+		nil,
+		"File patterns to ignore when watching files.")
+
+	LinkCmd.Flags().DurationVar(
+		&debounce,
+		"debounce",
+		link.DefaultDebounce,
+		"Time to wait for more changes before linking again.")
+
+	LinkCmd.Flags().IntVar(
+		&parallel,
+		"parallel",
+		workerpool.DefaultParallel,
+		"Number of containers to link at the same time.")
+
+	LinkCmd.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		0,
+		"Time to wait for each container to be linked and up before failing.")
+
+	LinkCmd.Flags().BoolVar(
+		&prune,
+		"prune",
+		false,
+		"Unlink containers that no longer exist on the project directory.")
+
+	LinkCmd.Flags().BoolVarP(
+		&yes,
+		"yes",
+		"y",
+		false,
+		"Unlink orphan containers without asking for confirmation.")
+
+	LinkCmd.Flags().StringVar(
+		&env,
+		"env",
+		"",
+		"Named local infrastructure to use (same as --local).")
 }
 
-func getContainersDirectoriesFromScope() ([]string, error) {
-	if config.Context.ContainerRoot != "" {
+func getContainersDirectoriesFromScope(selection link.Selection) ([]string, error) {
+	if config.Context.ContainerRoot != "" && selection.IsEmpty() {
 		_, container := filepath.Split(config.Context.ContainerRoot)
 		return []string{container}, nil
 	}
@@ -43,50 +137,169 @@ func getContainersDirectoriesFromScope() ([]string, error) {
 	var list, err = containers.GetListFromDirectory(config.Context.ProjectRoot)
 
 	if err != nil {
-		err = errwrap.Wrapf("Error retrieving containers list from directory: {{err}}", err)
+		return nil, errwrap.Wrapf("Error retrieving containers list from directory: {{err}}", err)
+	}
+
+	if selection.IsEmpty() {
+		return list, nil
 	}
 
-	return list, err
+	return selection.Select(config.Context.ProjectRoot, list)
 }
 
 func linkRun(cmd *cobra.Command, args []string) error {
-	if _, _, err := cmdcontext.GetProjectOrContainerID(args); err != nil {
+	if _, err := cmdcontext.GetProjectID([]string{}); err != nil {
 		return err
 	}
 
-	var csDirs, err = getContainersDirectoriesFromScope()
+	var selection = link.Selection{
+		IDs:     args,
+		Exclude: exclude,
+		Tags:    tags,
+	}
+
+	if quiet && watchFiles {
+		return errors.New("Can't watch files when linking quietly.")
+	}
+
+	if err := validateProject(); err != nil {
+		return err
+	}
+
+	var csDirs, err = getContainersDirectoriesFromScope(selection)
 
 	if err != nil {
 		return err
 	}
 
+	if len(csDirs) == 0 {
+		return errors.New("No containers to link.")
+	}
+
 	var m = &link.Machine{
 		FErrStream: os.Stderr,
+		DryRun:     dryRun,
+		Parallel:   parallel,
+		Timeout:    timeout,
 	}
 
 	if err = m.Setup(config.Context.ProjectRoot, csDirs); err != nil {
 		return err
 	}
 
+	if dryRun {
+		return printPlans(m)
+	}
+
 	if quiet {
-		m.Run()
+		trackContainers(run.TrackWait, func() {
+			m.Run()
+		})
+
+		register(m)
+		workerpool.PrintSummary(os.Stdout, "linked", m.Results())
+		return pruneOrphans(m)
+	}
+
+	var fw *link.FileWatcher
+
+	if watchFiles {
+		if fw, err = startFileWatcher(m); err != nil {
+			return err
+		}
+	}
+
+	// the watcher ends when the containers are up, so they exist already
+	trackContainers(0, func() {
+		var queue sync.WaitGroup
+
+		queue.Add(1)
+
+		go func() {
+			m.Run()
+		}()
+
+		go func() {
+			m.Watch()
+			queue.Done()
+		}()
+
+		queue.Wait()
+	})
+
+	if fw != nil {
+		if err = fw.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	register(m)
+	workerpool.PrintSummary(os.Stdout, "linked", m.Results())
+
+	var errPrune = pruneOrphans(m)
+
+	if len(m.Errors.List) != 0 {
+		return m.Errors
+	}
+
+	return errPrune
+}
+
+// trackContainers records the containers created by linking to a named local infrastructure
+func trackContainers(wait time.Duration, linking func()) {
+	var err = run.TrackContainers(config.Context.Local, wait, func() error {
+		linking()
 		return nil
+	})
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// validateProject validates the project definitions before linking
+// unknown fields (i.e., legacy fields) are only warned about
+func validateProject() error {
+	var err = schema.ValidateProject(config.Context.ProjectRoot)
+	var es, ok = err.(schema.Errors)
+
+	if !ok {
+		return err
+	}
+
+	var unknown, other = schema.SplitUnknownFields(es)
+
+	if len(other) != 0 {
+		return other
 	}
 
-	var queue sync.WaitGroup
+	for _, e := range unknown {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", e)
+	}
 
-	queue.Add(1)
+	return nil
+}
 
-	go func() {
-		m.Run()
-	}()
+func printPlans(m *link.Machine) error {
+	var plans, err = m.Plan()
 
-	go func() {
-		m.Watch()
-		queue.Done()
-	}()
+	if err != nil {
+		return err
+	}
 
-	queue.Wait()
+	link.PrintPlans(os.Stdout, plans)
+
+	if prune {
+		var orphans []string
+
+		if orphans, err = link.Orphans(m.Project.ID, m.ProjectPath); err != nil {
+			return err
+		}
+
+		for _, id := range orphans {
+			fmt.Fprintf(os.Stdout, "- %v would be unlinked\n", id)
+		}
+	}
 
 	if len(m.Errors.List) != 0 {
 		return m.Errors
@@ -94,3 +307,99 @@ func linkRun(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// pruneOrphans unlinks the containers that no longer exist locally
+func pruneOrphans(m *link.Machine) error {
+	if !prune {
+		return nil
+	}
+
+	var orphans, err = link.Orphans(m.Project.ID, m.ProjectPath)
+
+	if err != nil {
+		return err
+	}
+
+	if len(orphans) == 0 {
+		fmt.Println("No orphan containers to unlink.")
+		return nil
+	}
+
+	var confirmed bool
+
+	if confirmed, err = confirmPrune(orphans); err != nil || !confirmed {
+		return err
+	}
+
+	unlinked, err := link.Prune(m.Project.ID, orphans)
+	forget(m.Project.ID, unlinked)
+
+	for _, id := range unlinked {
+		fmt.Printf("Container %v unlinked.\n", id)
+	}
+
+	return err
+}
+
+func confirmPrune(orphans []string) (bool, error) {
+	if yes {
+		return true, nil
+	}
+
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return false, errors.New("No terminal (/dev/tty) detected for confirming. Use --yes to unlink orphan containers.")
+	}
+
+	fmt.Printf("Containers no longer on the project directory: %v\n",
+		strings.Join(orphans, ", "))
+
+	var q = prompt.Prompt("Unlink them [no]")
+
+	if q != "y" && q != "yes" {
+		fmt.Println("Orphan containers were not unlinked.")
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// forget removes the unlinked containers from the linked containers registry
+func forget(projectID string, unlinked []string) {
+	var r, err = link.GetRegistry()
+
+	if err == nil {
+		for _, id := range unlinked {
+			r.RemoveLocal(config.Context.Local, projectID, id)
+		}
+
+		err = r.Save()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// startFileWatcher watches the containers files
+// and keeps the status display live until the user stops it
+func startFileWatcher(m *link.Machine) (*link.FileWatcher, error) {
+	var fw = link.NewFileWatcher(m, ignore)
+	fw.Debounce = debounce
+	m.KeepWatching = true
+	return fw, fw.Start()
+}
+
+// register the linked containers so they can be linked again
+// when the infrastructure is upgraded
+func register(m *link.Machine) {
+	var r, err = link.GetRegistry()
+
+	if err == nil {
+		m.Register(r)
+		err = r.Save()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
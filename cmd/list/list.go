@@ -16,6 +16,7 @@ var ListCmd = &cobra.Command{
 var (
 	detailed bool
 	watch    bool
+	env      string
 )
 
 func listRun(cmd *cobra.Command, args []string) {
@@ -48,4 +49,8 @@ func init() {
 		"detailed", "d", false, "Show more containers details")
 
 	ListCmd.Flags().BoolVarP(&watch, "watch", "w", false, "Watch for changes")
+
+	ListCmd.Flags().StringVar(
+		&env,
+		"env", "", "Named local infrastructure to use (same as --local)")
 }
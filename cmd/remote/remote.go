@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/overlay"
 	"github.com/wedeploy/cli/verbose"
 )
 
@@ -49,6 +50,13 @@ var setURLCmd = &cobra.Command{
 	RunE:  setURLRun,
 }
 
+var setProfileCmd = &cobra.Command{
+	Use:     "set-profile",
+	Short:   "Changes the definitions profile used with the remote",
+	Example: "we remote set-profile staging staging",
+	RunE:    setProfileRun,
+}
+
 func remoteRun(cmd *cobra.Command, args []string) error {
 	if len(args) != 0 {
 		return errors.New("Invalid number of arguments.")
@@ -107,6 +115,7 @@ func renameRun(cmd *cobra.Command, args []string) error {
 	}
 
 	remotes.Set(name, oldRemote.URL, oldRemote.Comment)
+	remotes.SetProfile(name, oldRemote.Profile)
 	remotes.Del(old)
 	return global.Save()
 }
@@ -169,4 +178,31 @@ func init() {
 	RemoteCmd.AddCommand(removeCmd)
 	RemoteCmd.AddCommand(getURLCmd)
 	RemoteCmd.AddCommand(setURLCmd)
+	RemoteCmd.AddCommand(setProfileCmd)
+}
+
+func setProfileRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return errors.New("Invalid number of arguments.")
+	}
+
+	var global = config.Global
+	var remotes = global.Remotes
+	var name = args[0]
+	var profile string
+
+	if len(args) == 2 {
+		profile = args[1]
+	}
+
+	if _, ok := remotes.Get(name); !ok {
+		return errors.New("fatal: remote " + name + " doesn't exists.")
+	}
+
+	if profile != "" && !overlay.IsValidProfile(profile) {
+		return errors.New("Invalid profile " + profile + ".")
+	}
+
+	remotes.SetProfile(name, profile)
+	return global.Save()
 }
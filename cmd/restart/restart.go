@@ -1,26 +1,45 @@
 package cmdrestart
 
 import (
+	"errors"
+	"os"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/wedeploy/cli/cmdcontext"
+	"github.com/wedeploy/cli/config"
 	"github.com/wedeploy/cli/containers"
 	"github.com/wedeploy/cli/list"
 	"github.com/wedeploy/cli/projects"
+	"github.com/wedeploy/cli/restart"
+	"github.com/wedeploy/cli/run"
 	"github.com/wedeploy/cli/verbose"
+	"github.com/wedeploy/cli/workerpool"
 )
 
 // RestartCmd is used for getting restart
 var RestartCmd = &cobra.Command{
-	Use:   "restart [project] [container]",
+	Use:   "restart [project] [container...]",
 	Short: "Restart project or container running on WeDeploy",
 	RunE:  restartRun,
 	Example: `we restart portal
-we restart portal email`,
+we restart portal email
+we restart portal email search --parallel 2 --timeout 1m
+we restart portal email --rolling
+we restart portal --all --rolling`,
 }
 
-var quiet bool
+var (
+	quiet    bool
+	parallel int
+	timeout  time.Duration
+	rolling  bool
+	all      bool
+)
+
+var checkInterval = time.Second
 
 func init() {
 	RestartCmd.Flags().BoolVarP(
@@ -29,108 +48,245 @@ func init() {
 		"q",
 		false,
 		"Reset without watching status.")
+
+	RestartCmd.Flags().IntVar(
+		&parallel,
+		"parallel",
+		workerpool.DefaultParallel,
+		"Number of containers to restart at the same time.")
+
+	RestartCmd.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		0,
+		"Time to wait for each container to be up before failing.")
+
+	RestartCmd.Flags().BoolVar(
+		&rolling,
+		"rolling",
+		false,
+		"Restart the instances of each container one at a time.")
+
+	RestartCmd.Flags().BoolVar(
+		&all,
+		"all",
+		false,
+		"Restart all containers of the project one at a time.")
 }
 
-type restart struct {
-	project   string
-	container string
-	list      *list.List
-	err       error
-	end       bool
+type restarter struct {
+	project    string
+	containers []string
+	list       *list.List
+	// results and end are set by do, which runs while the list is watched
+	results []workerpool.Result
+	end     bool
+	mutex   sync.Mutex
 }
 
-func (r *restart) do() {
-	switch r.container {
-	case "":
-		r.err = projects.Restart(r.project)
-	default:
-		r.err = containers.Restart(r.project, r.container)
+// do restarts the containers (or the project, if there are no containers)
+func (r *restarter) do() {
+	var tasks = []workerpool.Task{}
+
+	if len(r.containers) == 0 {
+		tasks = append(tasks, workerpool.Task{
+			Name: r.project,
+			Run:  r.restartProject,
+		})
+	}
+
+	for _, c := range r.containers {
+		tasks = append(tasks, workerpool.Task{
+			Name: c,
+			Run:  r.restartContainer(c),
+		})
 	}
 
+	var results = workerpool.Run(tasks, parallel, timeout)
+
+	r.mutex.Lock()
+	r.results = results
 	r.end = true
+	r.mutex.Unlock()
+}
+
+// getResults gets the results of restarting (nil if it hasn't ended yet)
+func (r *restarter) getResults() []workerpool.Result {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.results
 }
 
-func (r *restart) isDone() bool {
-	if !r.end {
-		return false
+func (r *restarter) restartProject(cancel <-chan struct{}) error {
+	if err := projects.Restart(r.project); err != nil {
+		return err
 	}
 
-	if len(r.list.Projects) == 0 {
-		verbose.Debug("Unexpected behavior: no projects found.")
-		return false
+	return waitUp(cancel, func() (bool, error) {
+		var p, err = projects.Get(r.project)
+		return p.Health == "up", err
+	})
+}
+
+func (r *restarter) restartContainer(container string) func(cancel <-chan struct{}) error {
+	return func(cancel <-chan struct{}) error {
+		var instances, err = restart.Instances(r.project, container)
+
+		if err != nil {
+			return err
+		}
+
+		if err = containers.Restart(r.project, container); err != nil {
+			return err
+		}
+
+		// the container is still up until its instances are replaced
+		return waitUp(cancel, func() (bool, error) {
+			return restart.IsRestarted(r.project, container, instances)
+		})
 	}
+}
 
-	if r.container == "" && (r.list.Projects[0]).Health == "up" {
-		return true
+// waitUp waits until the project or container is up after restarting
+// (or until cancel is closed)
+func waitUp(cancel <-chan struct{}, isUp func() (bool, error)) error {
+	if quiet {
+		return nil
 	}
 
-	c, ok := r.list.Projects[0].Containers[r.container]
+	for {
+		var up, err = isUp()
+
+		if err != nil {
+			verbose.Debug("Can't get restarting status: " + err.Error())
+		}
+
+		if err == nil && up {
+			return nil
+		}
 
-	if !ok {
-		verbose.Debug("Unexpected behavior: no container found.")
-		return false
+		select {
+		case <-cancel:
+			return nil
+		case <-time.After(checkInterval):
+		}
 	}
+}
 
-	return c.Health == "up"
+func (r *restarter) isDone() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.end
 }
 
-func (r *restart) checkProjectOrContainerExists() error {
-	var err error
-	if r.container == "" {
-		_, err = projects.Get(r.project)
-	} else {
-		_, err = containers.Get(r.project, r.container)
+func (r *restarter) checkProjectOrContainersExist() error {
+	if len(r.containers) == 0 {
+		_, err := projects.Get(r.project)
+		return err
 	}
 
-	return err
+	for _, c := range r.containers {
+		if _, err := containers.Get(r.project, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func restartRun(cmd *cobra.Command, args []string) error {
-	project, container, err := cmdcontext.GetProjectOrContainerID(args)
+	project, cs, err := cmdcontext.GetProjectAndContainersIDs(args)
 
 	if err != nil {
 		return err
 	}
 
-	var r = &restart{
-		project:   project,
-		container: container,
+	var r = &restarter{
+		project:    project,
+		containers: cs,
 	}
 
-	if err = r.checkProjectOrContainerExists(); err != nil {
+	if err = r.checkProjectOrContainersExist(); err != nil {
 		return err
 	}
 
+	// restarting might create containers for a named local infrastructure
+	return run.TrackContainers(config.Context.Local, 0, r.restart)
+}
+
+func (r *restarter) restart() error {
+	if rolling || all {
+		return r.sequential()
+	}
+
 	if quiet {
 		r.do()
-		return r.err
+	} else {
+		var queue sync.WaitGroup
+
+		queue.Add(1)
+
+		go func() {
+			r.do()
+		}()
+
+		go func() {
+			r.watch()
+			queue.Done()
+		}()
+
+		queue.Wait()
+	}
+
+	if !r.isDone() {
+		return errors.New("Stopped watching before restarting ended: check its status with \"we list\".")
 	}
 
-	var queue sync.WaitGroup
+	var results = r.getResults()
+	workerpool.PrintSummary(os.Stdout, "restarted", results)
+	return workerpool.Err(results)
+}
 
-	queue.Add(1)
+// sequential restarts the containers one at a time, waiting for each one to be up
+func (r *restarter) sequential() error {
+	if quiet {
+		return errors.New("Can't restart one container at a time without watching status.")
+	}
 
-	go func() {
-		r.do()
-	}()
+	var ids = r.containers
+
+	if all {
+		if len(ids) != 0 {
+			return errors.New("Can't use --all with a list of containers.")
+		}
+
+		var cs, err = containers.List(r.project)
+
+		if err != nil {
+			return err
+		}
 
-	go func() {
-		r.watch()
-		queue.Done()
-	}()
+		for id := range cs {
+			ids = append(ids, id)
+		}
 
-	queue.Wait()
-	return r.err
+		sort.Strings(ids)
+	}
+
+	if len(ids) == 0 {
+		return errors.New("Rolling restart requires a container or --all.")
+	}
+
+	var results = restart.Sequential(r.project, ids, rolling, timeout)
+	workerpool.PrintSummary(os.Stdout, "restarted", results)
+	return workerpool.Err(results)
 }
 
-func (r *restart) watch() {
+func (r *restarter) watch() {
 	var filter = list.Filter{}
 
 	filter.Project = r.project
-
-	if r.container != "" {
-		filter.Containers = []string{r.container}
-	}
+	filter.Containers = r.containers
 
 	r.list = list.New(filter)
 
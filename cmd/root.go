@@ -10,43 +10,62 @@ import (
 	"github.com/wedeploy/cli/apihelper"
 	"github.com/wedeploy/cli/cmd/auth"
 	"github.com/wedeploy/cli/cmd/build"
+	"github.com/wedeploy/cli/cmd/clone"
+	"github.com/wedeploy/cli/cmd/config"
+	"github.com/wedeploy/cli/cmd/convert"
 	"github.com/wedeploy/cli/cmd/createctx"
+	"github.com/wedeploy/cli/cmd/deploy"
+	"github.com/wedeploy/cli/cmd/env"
+	"github.com/wedeploy/cli/cmd/image"
 	"github.com/wedeploy/cli/cmd/link"
 	"github.com/wedeploy/cli/cmd/list"
 	"github.com/wedeploy/cli/cmd/logs"
 	"github.com/wedeploy/cli/cmd/remote"
 	"github.com/wedeploy/cli/cmd/restart"
 	"github.com/wedeploy/cli/cmd/run"
+	"github.com/wedeploy/cli/cmd/scale"
 	"github.com/wedeploy/cli/cmd/stop"
+	"github.com/wedeploy/cli/cmd/template"
 	"github.com/wedeploy/cli/cmd/unlink"
 	"github.com/wedeploy/cli/cmd/update"
+	"github.com/wedeploy/cli/cmd/validate"
 	"github.com/wedeploy/cli/cmd/version"
 	"github.com/wedeploy/cli/color"
 	"github.com/wedeploy/cli/config"
 	"github.com/wedeploy/cli/defaults"
+	"github.com/wedeploy/cli/overlay"
 	"github.com/wedeploy/cli/verbose"
 	"github.com/wedeploy/cli/verbosereq"
 )
 
 // WhitelistCmdsNoAuthentication for cmds that doesn't require authentication
 var WhitelistCmdsNoAuthentication = map[string]bool{
-	"login":   true,
-	"logout":  true,
-	"build":   true,
-	"deploy":  true,
-	"update":  true,
-	"version": true,
+	"login":    true,
+	"logout":   true,
+	"build":    true,
+	"update":   true,
+	"version":  true,
+	"validate": true,
+	"convert":  true,
+	"env":      true,
+
+	"config render": true,
+	"template list": true,
 }
 
 // ListNoRemoteFlags hides the globals non used --remote
 var ListNoRemoteFlags = map[string]bool{
-	"link":    true,
-	"unlink":  true,
-	"run":     true,
-	"stop":    true,
-	"remote":  true,
-	"update":  true,
-	"version": true,
+	"convert":  true,
+	"link":     true,
+	"unlink":   true,
+	"run":      true,
+	"stop":     true,
+	"image":    true,
+	"remote":   true,
+	"template": true,
+	"update":   true,
+	"validate": true,
+	"version":  true,
 }
 
 // LocalOnlyCommands for local-only commands
@@ -55,6 +74,7 @@ var LocalOnlyCommands = map[string]bool{
 	"unlink": true,
 	"run":    true,
 	"stop":   true,
+	"image":  true,
 }
 
 // RootCmd is the main command for the CLI
@@ -74,21 +94,32 @@ http://wedeploy.com`,
 var (
 	version bool
 	remote  string
+	local   string
+	profile string
 )
 
 var commands = []*cobra.Command{
 	cmdauth.LoginCmd,
 	cmdauth.LogoutCmd,
 	cmdcreate.CreateCmd,
+	cmdclone.CloneCmd,
 	cmdlogs.LogsCmd,
 	cmdlist.ListCmd,
 	cmdrestart.RestartCmd,
+	cmdscale.ScaleCmd,
+	cmdenv.EnvCmd,
 	cmdbuild.BuildCmd,
+	cmddeploy.DeployCmd,
 	cmdrun.RunCmd,
 	cmdstop.StopCmd,
+	cmdimage.ImageCmd,
 	cmdlink.LinkCmd,
 	cmdunlink.UnlinkCmd,
 	cmdremote.RemoteCmd,
+	cmdtemplate.TemplateCmd,
+	cmdvalidate.ValidateCmd,
+	cmdconfig.ConfigCmd,
+	cmdconvert.ConvertCmd,
 	cmdupdate.UpdateCmd,
 	cmdversion.VersionCmd,
 }
@@ -147,6 +178,14 @@ func init() {
 		&remote,
 		"remote", "", "Remote to use")
 
+	RootCmd.PersistentFlags().StringVar(
+		&local,
+		"local", "", "Named local infrastructure to use (see we run --name)")
+
+	RootCmd.PersistentFlags().StringVar(
+		&profile,
+		"profile", "", "Profile of the definition overlays to use (i.e., container.<profile>.json)")
+
 	RootCmd.Flags().BoolVar(
 		&version,
 		"version", false, "Print version information and quit")
@@ -160,11 +199,38 @@ func init() {
 	}
 }
 
+// EnvFlagCommands accept --env as --local to select a named local infrastructure
+// (we run uses --env for the infrastructure environment variables instead)
+var EnvFlagCommands = map[string]bool{
+	"list": true,
+	"link": true,
+}
+
+// readEnvFlag reads --env as --local for the commands on EnvFlagCommands
+func readEnvFlag(cmd *cobra.Command) error {
+	var f = cmd.Flags().Lookup("env")
+
+	if !EnvFlagCommands[cmd.Name()] || f == nil || !f.Changed {
+		return nil
+	}
+
+	if local != "" && local != f.Value.String() {
+		return errors.New("can not use --env and --local with different infrastructures")
+	}
+
+	local = f.Value.String()
+	return nil
+}
+
 func setEndpoint() error {
 	if isLocalCommandOnly() && remote != "" {
 		return errors.New("can not use command with a remote")
 	}
 
+	if local != "" && remote != "" {
+		return errors.New("can not use a named local infrastructure with a remote")
+	}
+
 	if remote == "" {
 		return setLocal()
 	}
@@ -173,8 +239,22 @@ func setEndpoint() error {
 }
 
 func setLocal() error {
+	var port = config.Global.LocalPort
+
+	if local != "" {
+		var l, ok = config.Global.Locals.Get(local)
+
+		if !ok {
+			return errors.New("Local infrastructure " + local + " is not configured. " +
+				"Use \"we run --name " + local + "\" to run it.")
+		}
+
+		port += l.PortOffset
+	}
+
+	config.Context.Local = local
 	config.Context.Token = apihelper.DefaultToken
-	config.Context.Endpoint = fmt.Sprintf("http://localhost:%d/", config.Global.LocalPort)
+	config.Context.Endpoint = fmt.Sprintf("http://localhost:%d/", port)
 	return nil
 }
 
@@ -212,7 +292,33 @@ func persistentPreRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	return setEndpoint()
+	if err := readEnvFlag(cmd); err != nil {
+		return err
+	}
+
+	if err := setEndpoint(); err != nil {
+		return err
+	}
+
+	return setProfile()
+}
+
+// setProfile sets the profile of the definition overlays
+// defaulting to the profile associated with the remote
+func setProfile() error {
+	var p = profile
+
+	if p == "" && remote != "" {
+		var r, _ = config.Global.Remotes.Get(remote)
+		p = r.Profile
+	}
+
+	if p != "" && !overlay.IsValidProfile(p) {
+		return errors.New("Invalid profile " + p + ".")
+	}
+
+	config.Context.Profile = p
+	return nil
 }
 
 func run(cmd *cobra.Command, args []string) {
@@ -2,8 +2,10 @@ package cmdrun
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/config"
 	"github.com/wedeploy/cli/run"
 )
 
@@ -12,6 +14,13 @@ var RunCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Run WeDeploy infrastructure for development locally",
 	RunE:  runRun,
+	Example: `we run
+we run --memory 2g --cpus 1.5
+we run --env LOG_LEVEL=debug --volume /tmp/data:/data --network ci
+we run --image-archive wedeploy-local.tar
+we run --logs
+we run --upgrade
+we run --name staging --detach`,
 }
 
 var (
@@ -19,6 +28,15 @@ var (
 	dryRun   bool
 	viewMode bool
 	noUpdate bool
+	memory   string
+	cpus     string
+	env      []string
+	volumes  []string
+	network  string
+	archive  string
+	logs     bool
+	upgrade  bool
+	name     string
 )
 
 func runRun(cmd *cobra.Command, args []string) error {
@@ -26,12 +44,99 @@ func runRun(cmd *cobra.Command, args []string) error {
 		return errors.New("Invalid number of arguments.")
 	}
 
-	return run.Run(run.Flags{
-		Detach:   detach,
-		DryRun:   dryRun,
-		ViewMode: viewMode,
-		NoUpdate: noUpdate,
-	})
+	var flags = run.Flags{
+		Detach:       detach,
+		DryRun:       dryRun,
+		ViewMode:     viewMode,
+		NoUpdate:     noUpdate,
+		Memory:       memory,
+		CPUs:         cpus,
+		Env:          env,
+		Volumes:      volumes,
+		Network:      network,
+		ImageArchive: archive,
+		Logs:         logs,
+		Upgrade:      upgrade,
+		Name:         name,
+	}
+
+	useConfigDefaults(cmd, &flags)
+
+	// we run --local <name> runs an already configured named local infrastructure
+	if flags.Name == "" {
+		flags.Name = config.Context.Local
+	}
+
+	if flags.Name != "" {
+		if err := useNamedLocal(&flags); err != nil {
+			return err
+		}
+	}
+
+	if err := run.Run(flags); err != nil {
+		return err
+	}
+
+	// running on the foreground only returns when the infrastructure is shutdown
+	if flags.Name != "" && !flags.Detach && !flags.ViewMode {
+		config.Global.Locals.Del(flags.Name)
+		return config.Global.Save()
+	}
+
+	return nil
+}
+
+// useNamedLocal sets the port offset of a named local infrastructure,
+// allocating one for a new name, and points the context to its endpoint
+func useNamedLocal(flags *run.Flags) error {
+	var locals = &config.Global.Locals
+	var l, ok = locals.Get(flags.Name)
+
+	if !ok {
+		l.PortOffset = locals.NextPortOffset()
+		locals.Set(flags.Name, l.PortOffset)
+
+		if !flags.DryRun {
+			if err := config.Global.Save(); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("Local infrastructure %v uses port offset %d.\n", flags.Name, l.PortOffset)
+	}
+
+	flags.PortOffset = l.PortOffset
+	config.Context.Local = flags.Name
+	config.Context.Endpoint = fmt.Sprintf("http://localhost:%d/",
+		config.Global.LocalPort+l.PortOffset)
+	return nil
+}
+
+// useConfigDefaults fills the flags not passed on the command line
+// with the values from the configuration file
+func useConfigDefaults(cmd *cobra.Command, flags *run.Flags) {
+	var g = config.Global
+	var changed = cmd.Flags().Changed
+
+	if !changed("memory") {
+		flags.Memory = g.LocalMemory
+	}
+
+	if !changed("cpus") {
+		flags.CPUs = g.LocalCPUs
+	}
+
+	if !changed("env") {
+		flags.Env = g.LocalEnv
+	}
+
+	if !changed("volume") {
+		flags.Volumes = g.LocalVolumes
+	}
+
+	if !changed("network") {
+		flags.Network = g.LocalNetwork
+	}
 }
 
 func init() {
@@ -46,4 +151,31 @@ func init() {
 
 	RunCmd.Flags().BoolVar(&noUpdate, "no-update", false,
 		"Don't try to update the docker image")
+
+	RunCmd.Flags().StringVar(&memory, "memory", "",
+		"Memory limit for the infrastructure (i.e., 512m, 2g)")
+
+	RunCmd.Flags().StringVar(&cpus, "cpus", "",
+		"Number of CPUs for the infrastructure (i.e., 1.5)")
+
+	RunCmd.Flags().StringArrayVarP(&env, "env", "e", nil,
+		"Extra environment variables for the infrastructure (KEY=value)")
+
+	RunCmd.Flags().StringArrayVar(&volumes, "volume", nil,
+		"Extra volumes to mount on the infrastructure (source:destination)")
+
+	RunCmd.Flags().StringVar(&network, "network", "",
+		"Docker network to connect the infrastructure to")
+
+	RunCmd.Flags().StringVar(&archive, "image-archive", "",
+		"Load the infrastructure docker image from a \"we image save\" archive")
+
+	RunCmd.Flags().BoolVar(&logs, "logs", false,
+		"Show the infrastructure logs after it starts")
+
+	RunCmd.Flags().BoolVar(&upgrade, "upgrade", false,
+		"Upgrade a running outdated infrastructure and link its projects again")
+
+	RunCmd.Flags().StringVar(&name, "name", "",
+		"Run a named local infrastructure (use --local to target it on other commands)")
 }
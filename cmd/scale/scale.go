@@ -0,0 +1,318 @@
+package cmdscale
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/cmdcontext"
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/list"
+	"github.com/wedeploy/cli/projects"
+	"github.com/wedeploy/cli/run"
+	"github.com/wedeploy/cli/verbose"
+)
+
+// ScaleCmd changes the number of instances of containers
+var ScaleCmd = &cobra.Command{
+	Use:   "scale",
+	Short: "Change the number of instances of containers",
+	RunE:  scaleRun,
+	Example: `we scale <container> <instances>
+we scale <project> <container> <instances>
+we scale <project> <container>=<instances> [<container>=<instances>...]
+we scale email 3 --save`,
+}
+
+var (
+	quiet bool
+	save  bool
+)
+
+func init() {
+	ScaleCmd.Flags().BoolVarP(
+		&quiet,
+		"quiet",
+		"q",
+		false,
+		"Scale without watching status.")
+
+	ScaleCmd.Flags().BoolVar(
+		&save,
+		"save",
+		false,
+		"Save the number of instances on the container.json files.")
+}
+
+type scale struct {
+	container string
+	instances int
+}
+
+type scaling struct {
+	project string
+	scales  []scale
+	list    *list.List
+	// err and end are set by do, which runs while the list is watched
+	err   error
+	end   bool
+	mutex sync.Mutex
+}
+
+// parseArgs parses <container> <n>, <project> <container> <n>
+// and <project> <container>=<n> [<container>=<n>...]
+func parseArgs(args []string) (s *scaling, err error) {
+	s = &scaling{}
+
+	switch {
+	case len(args) >= 2 && strings.Contains(args[1], "="):
+		s.project = args[0]
+		return s, s.parseBatch(args[1:])
+	case len(args) == 2:
+		if s.project, err = cmdcontext.GetProjectID([]string{}); err != nil {
+			return nil, err
+		}
+
+		return s, s.add(args[0], args[1])
+	case len(args) == 3:
+		s.project = args[0]
+		return s, s.add(args[1], args[2])
+	default:
+		return nil, errors.New("Invalid arguments: use <container> <instances> or <project> <container>=<instances>.")
+	}
+}
+
+func (s *scaling) parseBatch(args []string) error {
+	for _, arg := range args {
+		var kv = strings.SplitN(arg, "=", 2)
+
+		if len(kv) != 2 {
+			return fmt.Errorf("Invalid argument %v: use <container>=<instances>.", arg)
+		}
+
+		if err := s.add(kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *scaling) add(container, instances string) error {
+	var n, err = strconv.Atoi(instances)
+
+	if err != nil {
+		return fmt.Errorf("Invalid number of instances %v for container %v.", instances, container)
+	}
+
+	for _, sc := range s.scales {
+		if sc.container == container {
+			return fmt.Errorf("Container %v is repeated.", container)
+		}
+	}
+
+	s.scales = append(s.scales, scale{
+		container: container,
+		instances: n,
+	})
+
+	return nil
+}
+
+func (s *scaling) validate() error {
+	if _, err := projects.Get(s.project); err != nil {
+		return err
+	}
+
+	for _, sc := range s.scales {
+		if _, err := containers.Get(s.project, sc.container); err != nil {
+			return err
+		}
+
+		if err := containers.ValidateInstances(s.project, sc.container, sc.instances); err != nil {
+			return errwrap.Wrapf("Can't scale container "+sc.container+": {{err}}", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *scaling) do() {
+	var err error
+
+	for _, sc := range s.scales {
+		if err = containers.Scale(s.project, sc.container, sc.instances); err != nil {
+			break
+		}
+	}
+
+	s.mutex.Lock()
+	s.err = err
+	s.end = true
+	s.mutex.Unlock()
+}
+
+// getStatus gets if scaling has ended and its error
+func (s *scaling) getStatus() (end bool, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.end, s.err
+}
+
+// persist saves the number of instances on the local container.json files
+func (s *scaling) persist() error {
+	if config.Context.ProjectRoot == "" {
+		return errors.New("Can't save instances: not on a project directory.")
+	}
+
+	var project, err = cmdcontext.GetProjectID([]string{})
+
+	if err != nil {
+		return errwrap.Wrapf("Can't save instances: {{err}}", err)
+	}
+
+	if project != s.project {
+		return fmt.Errorf("Can't save instances: project %v is not the project on this directory (%v).",
+			s.project, project)
+	}
+
+	dirs, err := containers.GetListFromDirectory(config.Context.ProjectRoot)
+
+	if err != nil {
+		return errwrap.Wrapf("Can't save instances: {{err}}", err)
+	}
+
+	var paths = map[string]string{}
+
+	for _, dir := range dirs {
+		var path = filepath.Join(config.Context.ProjectRoot, dir)
+		var c, err = containers.ReadRaw(path)
+
+		if err != nil {
+			return errwrap.Wrapf("Can't save instances: {{err}}", err)
+		}
+
+		paths[c.ID] = path
+	}
+
+	for _, sc := range s.scales {
+		var path, ok = paths[sc.container]
+
+		if !ok {
+			return fmt.Errorf("Can't save instances: container %v not found on project directory.", sc.container)
+		}
+
+		if err = containers.SetInstances(path, sc.instances); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *scaling) isDone() bool {
+	var end, err = s.getStatus()
+
+	if !end || err != nil {
+		return end
+	}
+
+	for _, sc := range s.scales {
+		if !isScaled(s.project, sc) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isScaled checks if a container has the requested number of instances up
+// (its number of instances changes before the new instances run)
+func isScaled(project string, sc scale) bool {
+	var instances, err = containers.Instances(project, sc.container)
+
+	if err != nil {
+		verbose.Debug("Can't get scaling status: " + err.Error())
+		return false
+	}
+
+	var up int
+
+	for _, i := range instances {
+		if i.Health == "up" {
+			up++
+		}
+	}
+
+	return len(instances) == sc.instances && up == sc.instances
+}
+
+func (s *scaling) watch() {
+	var filter = list.Filter{
+		Project: s.project,
+	}
+
+	for _, sc := range s.scales {
+		filter.Containers = append(filter.Containers, sc.container)
+	}
+
+	s.list = list.New(filter)
+	s.list.Detailed = true
+
+	var watcher = list.NewWatcher(s.list)
+	watcher.StopCondition = s.isDone
+	watcher.Start()
+}
+
+func scaleRun(cmd *cobra.Command, args []string) error {
+	var s, err = parseArgs(args)
+
+	if err != nil {
+		return err
+	}
+
+	if err = s.validate(); err != nil {
+		return err
+	}
+
+	// scaling creates containers for a named local infrastructure
+	return run.TrackContainers(config.Context.Local, 0, s.scale)
+}
+
+func (s *scaling) scale() error {
+	if quiet {
+		s.do()
+	} else {
+		var queue sync.WaitGroup
+
+		queue.Add(1)
+
+		go func() {
+			s.do()
+		}()
+
+		go func() {
+			s.watch()
+			queue.Done()
+		}()
+
+		queue.Wait()
+	}
+
+	var end, err = s.getStatus()
+
+	switch {
+	case !end:
+		return errors.New("Stopped watching before scaling ended: check its status with \"we list\".")
+	case err != nil || !save:
+		return err
+	}
+
+	return s.persist()
+}
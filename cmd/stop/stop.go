@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/config"
 	"github.com/wedeploy/cli/run"
 )
 
@@ -20,5 +21,22 @@ func stopRun(cmd *cobra.Command, args []string) error {
 		return errors.New("This command doesn't take arguments.")
 	}
 
-	return run.Stop()
+	var local = config.Context.Local
+
+	if err := run.Stop(local); err != nil {
+		return err
+	}
+
+	return forgetLocal(local)
+}
+
+// forgetLocal removes a stopped named local infrastructure from the configuration
+func forgetLocal(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	var global = config.Global
+	global.Locals.Del(name)
+	return global.Save()
 }
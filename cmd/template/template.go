@@ -0,0 +1,54 @@
+package cmdtemplate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/templates"
+	"github.com/wedeploy/cli/verbose"
+)
+
+// TemplateCmd is used for managing container templates
+var TemplateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Container templates for creating containers",
+	Long: `Container templates are directories of files instantiated by "we create".
+Files and paths are Go templates with the fields .ID, .Name, .Type and .ProjectID.
+Templates are read from the directories on the templates_dirs key of ~/.we
+followed by ~/.we_templates.`,
+}
+
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the available container templates",
+	Example: "we template list",
+	RunE:    listRun,
+}
+
+func init() {
+	TemplateCmd.AddCommand(listCmd)
+}
+
+func listRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return errors.New("Invalid number of arguments.")
+	}
+
+	var list, err = templates.List(templates.Dirs())
+
+	if err != nil {
+		return err
+	}
+
+	for _, t := range list {
+		switch verbose.Enabled {
+		case true:
+			fmt.Printf("%s\t%s\n", t.Name, t.Path)
+		default:
+			fmt.Println(t.Name)
+		}
+	}
+
+	return nil
+}
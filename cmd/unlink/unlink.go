@@ -1,14 +1,23 @@
 package cmdunlink
 
 import (
+	"errors"
+	"fmt"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/apihelper"
 	"github.com/wedeploy/cli/cmdcontext"
+	"github.com/wedeploy/cli/config"
 	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/link"
 	"github.com/wedeploy/cli/list"
 	"github.com/wedeploy/cli/projects"
+	"github.com/wedeploy/cli/verbose"
+	"github.com/wedeploy/cli/workerpool"
 )
 
 // UnlinkCmd unlinks the given project or container locally
@@ -18,11 +27,18 @@ var UnlinkCmd = &cobra.Command{
 	RunE:  unlinkRun,
 	Example: `we unlink
 we unlink <project>
-we unlink <project> <container>
-we unlink <container>`,
+we unlink <project> <container> [<container>...]
+we unlink <container>
+we unlink <project> <container> <container> --parallel 2 --timeout 1m`,
 }
 
-var quiet bool
+var (
+	quiet    bool
+	parallel int
+	timeout  time.Duration
+)
+
+var checkInterval = time.Second
 
 func init() {
 	UnlinkCmd.Flags().BoolVarP(
@@ -31,51 +47,153 @@ func init() {
 		"q",
 		false,
 		"Unlink without watching status.")
+
+	UnlinkCmd.Flags().IntVar(
+		&parallel,
+		"parallel",
+		workerpool.DefaultParallel,
+		"Number of containers to unlink at the same time.")
+
+	UnlinkCmd.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		0,
+		"Time to wait for each container to be unlinked before failing.")
 }
 
 type unlink struct {
-	project   string
-	container string
-	list      *list.List
-	end       bool
-	err       error
+	project    string
+	containers []string
+	list       *list.List
+	// results and end are set by do, which runs while the list is watched
+	results []workerpool.Result
+	end     bool
+	mutex   sync.Mutex
 }
 
+// do unlinks the containers (or the project, if there are no containers)
 func (u *unlink) do() {
-	switch u.container {
-	case "":
-		u.err = projects.Unlink(u.project)
-	default:
-		u.err = containers.Unlink(u.project, u.container)
+	var tasks = []workerpool.Task{}
+
+	for _, c := range u.getTargets() {
+		tasks = append(tasks, u.getTask(c))
 	}
 
+	var results = workerpool.Run(tasks, parallel, timeout)
+
+	u.mutex.Lock()
+	u.results = results
 	u.end = true
+	u.mutex.Unlock()
 }
 
-func (u *unlink) isDone() bool {
-	if !u.end {
-		return false
+// getResults gets the results of unlinking (nil if it hasn't ended yet)
+func (u *unlink) getResults() []workerpool.Result {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	return u.results
+}
+
+func (u *unlink) getTargets() []string {
+	if len(u.containers) == 0 {
+		return []string{""}
 	}
 
-	if len(u.list.Projects) == 0 {
-		return true
+	return u.containers
+}
+
+func (u *unlink) getTask(container string) workerpool.Task {
+	if container == "" {
+		return workerpool.Task{
+			Name: u.project,
+			Run: func(cancel <-chan struct{}) error {
+				if err := projects.Unlink(u.project); err != nil {
+					return err
+				}
+
+				return waitGone(cancel, func() error {
+					_, err := projects.Get(u.project)
+					return err
+				})
+			},
+		}
 	}
 
-	if u.container != "" && u.list.Projects[0].Containers[u.container] == nil {
-		return true
+	return workerpool.Task{
+		Name: container,
+		Run: func(cancel <-chan struct{}) error {
+			if err := containers.Unlink(u.project, container); err != nil {
+				return err
+			}
+
+			return waitGone(cancel, func() error {
+				_, err := containers.Get(u.project, container)
+				return err
+			})
+		},
 	}
+}
 
-	return false
+// waitGone waits until getting the project or container fails with not found
+// (or until cancel is closed)
+func waitGone(cancel <-chan struct{}, get func() error) error {
+	if quiet {
+		return nil
+	}
+
+	for {
+		var err = get()
+
+		if ae, ok := err.(*apihelper.APIFault); ok && ae.Code == 404 {
+			return nil
+		}
+
+		if err != nil {
+			verbose.Debug("Can't get unlinking status: " + err.Error())
+		}
+
+		select {
+		case <-cancel:
+			return nil
+		case <-time.After(checkInterval):
+		}
+	}
+}
+
+// forget removes the unlinked containers from the linked containers registry
+// only the targets unlinked without errors are removed
+func (u *unlink) forget(results []workerpool.Result) {
+	var r, err = link.GetRegistry()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+
+	var targets = u.getTargets()
+
+	for n, result := range results {
+		if n < len(targets) && result.Err == nil {
+			r.RemoveLocal(config.Context.Local, u.project, targets[n])
+		}
+	}
+
+	if err = r.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+func (u *unlink) isDone() bool {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	return u.end
 }
 
 func (u *unlink) watch() {
 	var filter = list.Filter{}
 
 	filter.Project = u.project
-
-	if u.container != "" {
-		filter.Containers = []string{u.container}
-	}
+	filter.Containers = u.containers
 
 	u.list = list.New(filter)
 
@@ -86,56 +204,62 @@ func (u *unlink) watch() {
 	watcher.Start()
 }
 
-func (u *unlink) checkProjectOrContainerExists() error {
-	var err error
-	if u.container == "" {
-		_, err = projects.Get(u.project)
-	} else {
-		_, err = containers.Get(u.project, u.container)
+func (u *unlink) checkProjectOrContainersExist() error {
+	if len(u.containers) == 0 {
+		_, err := projects.Get(u.project)
+		return err
+	}
+
+	for _, c := range u.containers {
+		if _, err := containers.Get(u.project, c); err != nil {
+			return err
+		}
 	}
 
-	return err
+	return nil
 }
 
 func unlinkRun(cmd *cobra.Command, args []string) error {
-	var project, container, err = cmdcontext.GetProjectOrContainerID(args)
+	var project, cs, err = cmdcontext.GetProjectAndContainersIDs(args)
 
 	if err != nil {
 		return errwrap.Wrapf("fatal: {{err}}", err)
 	}
 
 	var u = &unlink{
-		project:   project,
-		container: container,
+		project:    project,
+		containers: cs,
 	}
 
-	if err = u.checkProjectOrContainerExists(); err != nil {
+	if err = u.checkProjectOrContainersExist(); err != nil {
 		return err
 	}
 
 	if quiet {
 		u.do()
-		return err
-	}
-
-	var queue sync.WaitGroup
+	} else {
+		var queue sync.WaitGroup
 
-	queue.Add(1)
+		queue.Add(1)
 
-	go func() {
-		u.do()
-	}()
+		go func() {
+			u.do()
+		}()
 
-	go func() {
-		u.watch()
-		queue.Done()
-	}()
+		go func() {
+			u.watch()
+			queue.Done()
+		}()
 
-	queue.Wait()
+		queue.Wait()
+	}
 
-	if u.err != nil {
-		return u.err
+	if !u.isDone() {
+		return errors.New("Stopped watching before unlinking ended: check its status with \"we list\".")
 	}
 
-	return nil
+	var results = u.getResults()
+	u.forget(results)
+	workerpool.PrintSummary(os.Stdout, "unlinked", results)
+	return workerpool.Err(results)
 }
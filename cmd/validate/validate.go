@@ -0,0 +1,78 @@
+package cmdvalidate
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/schema"
+)
+
+// ValidateCmd validates the definition files of a project
+var ValidateCmd = &cobra.Command{
+	Use:   "validate [file...]",
+	Short: "Validate the project and containers definition files",
+	Long: `Use "we validate" to check the project, auth and container
+definition files (JSON or YAML) of the current project for unknown fields,
+invalid types, invalid IDs and duplicated containers IDs.`,
+	RunE: validateRun,
+	Example: `we validate
+we validate email/container.json`,
+}
+
+var schemas = map[string]*schema.Schema{
+	"project.json":   schema.Project,
+	"project.yaml":   schema.Project,
+	"container.json": schema.Container,
+	"container.yaml": schema.Container,
+	"auth.json":      schema.Auth,
+	"auth.yaml":      schema.Auth,
+}
+
+func validateRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return validateFiles(args)
+	}
+
+	if config.Context.Scope == "global" {
+		return errors.New("No project found. Run it inside a project or pass the files to validate.")
+	}
+
+	if err := schema.ValidateProject(config.Context.ProjectRoot); err != nil {
+		return err
+	}
+
+	fmt.Println("Project definitions are valid.")
+	return nil
+}
+
+func validateFiles(files []string) error {
+	var es = schema.Errors{}
+
+	for _, file := range files {
+		var s, ok = schemas[filepath.Base(file)]
+
+		if !ok {
+			return fmt.Errorf("Can't validate %v: not a project, container or auth definition file.", file)
+		}
+
+		var err = s.ValidateFile(file)
+
+		switch e := err.(type) {
+		case nil:
+		case schema.Errors:
+			es = append(es, e...)
+		default:
+			return err
+		}
+	}
+
+	if len(es) != 0 {
+		return es
+	}
+
+	fmt.Println("Definitions are valid.")
+	return nil
+}
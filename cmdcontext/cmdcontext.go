@@ -54,6 +54,22 @@ func GetProjectOrContainerID(args []string) (projectID, containerID string, err
 	}
 }
 
+// GetProjectAndContainersIDs gets the project and (optionally) its containers IDs
+func GetProjectAndContainersIDs(args []string) (projectID string, containersIDs []string, err error) {
+	if len(args) == 0 {
+		var containerID string
+		projectID, containerID, err = getCtxProjectOrContainerID()
+
+		if containerID != "" {
+			containersIDs = []string{containerID}
+		}
+
+		return projectID, containersIDs, err
+	}
+
+	return args[0], args[1:], nil
+}
+
 // SplitArguments splits a group of arguments (e.g., project + container)
 func SplitArguments(recArgs []string, offset, limit int) []string {
 	if len(recArgs) < limit {
@@ -100,7 +116,7 @@ func getCtxContainerID() (id string, err error) {
 	var path = config.Context.ContainerRoot
 	var container *containers.Container
 
-	container, err = containers.Read(path)
+	container, err = containers.ReadRaw(path)
 
 	if err != nil {
 		return "", err
@@ -169,6 +169,35 @@ func TestGetProjectOrContainerID(t *testing.T) {
 	}
 }
 
+func TestGetProjectAndContainersIDs(t *testing.T) {
+	var cases = []struct {
+		Args         []string
+		ProjectID    string
+		ContainersID []string
+		Err          error
+	}{
+		{[]string{}, "", nil, ErrContextNotFound},
+		{[]string{"x007"}, "x007", []string{}, nil},
+		{[]string{"x695", "y151", "z615"}, "x695", []string{"y151", "z615"}, nil},
+	}
+
+	for _, c := range cases {
+		project, containers, err := GetProjectAndContainersIDs(c.Args)
+
+		if project != c.ProjectID {
+			t.Errorf("Wanted project %v, got %v instead", c.ProjectID, project)
+		}
+
+		if !reflect.DeepEqual(containers, c.ContainersID) {
+			t.Errorf("Wanted containers %v, got %v instead", c.ContainersID, containers)
+		}
+
+		if err != c.Err {
+			t.Errorf("Wanted error %v, got %v instead", c.Err, err)
+		}
+	}
+}
+
 func TestGetProjectIDWithProjectStore(t *testing.T) {
 	var workingDir, _ = os.Getwd()
 	chdir("./mocks/project/")
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -22,6 +23,8 @@ type RemoteConfig struct {
 	URL        string
 	URLComment string
 	Comment    string
+	// Profile of the definition overlays used with the remote
+	Profile string
 }
 
 // Remotes (list of alternative endpoints)
@@ -59,14 +62,85 @@ func (r *Remotes) Set(name string, url string, comment ...string) {
 	r.list[name] = RemoteConfig{
 		URL:     url,
 		Comment: strings.Join(comment, " "),
+		Profile: r.list[name].Profile,
 	}
 }
 
+// SetProfile sets the profile of the definition overlays used with a remote
+func (r *Remotes) SetProfile(name, profile string) {
+	var remote = r.list[name]
+	remote.Profile = profile
+	r.list[name] = remote
+}
+
 // Del deletes a remote by name
 func (r *Remotes) Del(name string) {
 	delete(r.list, name)
 }
 
+// LocalConfig for a named local infrastructure
+type LocalConfig struct {
+	PortOffset int
+}
+
+// Locals (list of named local infrastructures)
+type Locals struct {
+	list localsList
+}
+
+type localsList map[string]LocalConfig
+
+// List locals
+func (l *Locals) List() []string {
+	var keys = make([]string, 0, len(l.list))
+
+	for k := range l.list {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Get a given local by name
+func (l *Locals) Get(name string) (LocalConfig, bool) {
+	local, ok := l.list[name]
+	return local, ok
+}
+
+// Set a local
+func (l *Locals) Set(name string, portOffset int) {
+	if l.list == nil {
+		l.list = localsList{}
+	}
+
+	l.list[name] = LocalConfig{
+		PortOffset: portOffset,
+	}
+}
+
+// Del deletes a local by name
+func (l *Locals) Del(name string) {
+	delete(l.list, name)
+}
+
+// NextPortOffset gets the lowest port offset not used by any local
+func (l *Locals) NextPortOffset() int {
+	var used = map[int]bool{}
+
+	for _, v := range l.list {
+		used[v.PortOffset] = true
+	}
+
+	var offset = 100
+
+	for used[offset] {
+		offset += 100
+	}
+
+	return offset
+}
+
 // Config of the application
 type Config struct {
 	Username        string    `ini:"username"`
@@ -74,14 +148,22 @@ type Config struct {
 	Token           string    `ini:"token"`
 	Local           bool      `ini:"local"`
 	LocalPort       int       `ini:"local_port"`
+	LocalMemory     string    `ini:"local_memory"`
+	LocalCPUs       string    `ini:"local_cpus"`
+	LocalEnv        []string  `ini:"-"`
+	LocalVolumes    []string  `ini:"-"`
+	LocalNetwork    string    `ini:"local_network"`
 	NoColor         bool      `ini:"disable_colors"`
 	Endpoint        string    `ini:"endpoint"`
 	NotifyUpdates   bool      `ini:"notify_updates"`
 	ReleaseChannel  string    `ini:"release_channel"`
 	LastUpdateCheck string    `ini:"last_update_check"`
 	NextVersion     string    `ini:"next_version"`
+	TemplatesDirs   []string  `ini:"templates_dirs" delim:","`
+	RegistrySources []string  `ini:"registry_sources" delim:","`
 	Path            string    `ini:"-"`
 	Remotes         Remotes   `ini:"-"`
+	Locals          Locals    `ini:"-"`
 	file            *ini.File `ini:"-"`
 }
 
@@ -119,7 +201,9 @@ func (c *Config) Save() error {
 		return errwrap.Wrapf("Can't load configuration: {{err}}", err)
 	}
 
+	c.updateLists()
 	c.updateRemotes()
+	c.updateLocals()
 	c.simplify()
 
 	err = cfg.SaveToIndent(c.Path, "    ")
@@ -178,6 +262,38 @@ func (c *Config) load() {
 	if err := c.file.MapTo(c); err != nil {
 		panic(err)
 	}
+
+	c.readLists()
+}
+
+// readLists reads the lists with one item per line
+// (their values might have commas, i.e., JAVA_OPTS=-Xms1g,-Xmx2g)
+func (c *Config) readLists() {
+	var mainSection = c.file.Section("")
+	c.LocalEnv = splitLines(mainSection.Key("local_env").String())
+	c.LocalVolumes = splitLines(mainSection.Key("local_volumes").String())
+}
+
+func (c *Config) updateLists() {
+	var mainSection = c.file.Section("")
+	mainSection.Key("local_env").SetValue(strings.Join(c.LocalEnv, "\n"))
+	mainSection.Key("local_volumes").SetValue(strings.Join(c.LocalVolumes, "\n"))
+}
+
+func splitLines(value string) []string {
+	var list = []string{}
+
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			list = append(list, line)
+		}
+	}
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	return list
 }
 
 func (c *Config) read() error {
@@ -190,11 +306,20 @@ func (c *Config) read() error {
 	}
 
 	c.readRemotes()
+	c.readLocals()
 	return nil
 }
 
 func parseRemoteSectionName(parsable string) (parsed string, is bool) {
-	var r = regexp.MustCompile(`remote \"(.*)\"`)
+	return parseSectionName("remote", parsable)
+}
+
+func parseLocalSectionName(parsable string) (parsed string, is bool) {
+	return parseSectionName("local", parsable)
+}
+
+func parseSectionName(kind, parsable string) (parsed string, is bool) {
+	var r = regexp.MustCompile(`^` + kind + ` \"(.*)\"`)
 	var matches = r.FindStringSubmatch(parsable)
 
 	if len(matches) == 2 {
@@ -244,13 +369,78 @@ func (c *Config) readRemotes() {
 			URL:        u.String(),
 			URLComment: strings.TrimSpace(URLComment),
 			Comment:    strings.TrimSpace(comment),
+			Profile:    s.Key("profile").String(),
+		}
+	}
+}
+
+func (c *Config) listLocals() []string {
+	var list = []string{}
+
+	for _, k := range c.file.SectionStrings() {
+		var key, is = parseLocalSectionName(k)
+
+		if !is {
+			continue
+		}
+
+		list = append(list, key)
+	}
+
+	return list
+}
+
+func (c *Config) getLocal(key string) *ini.Section {
+	return c.file.Section(`local "` + key + `"`)
+}
+
+func (c *Config) deleteLocal(key string) {
+	c.file.DeleteSection(`local "` + key + `"`)
+}
+
+func (c *Config) readLocals() {
+	c.Locals = Locals{
+		list: localsList{},
+	}
+
+	for _, k := range c.listLocals() {
+		var offset, err = c.getLocal(k).Key("port_offset").Int()
+
+		if err != nil {
+			verbose.Debug("Ignoring invalid port offset for local " + k + ": " + err.Error())
+		}
+
+		c.Locals.list[k] = LocalConfig{
+			PortOffset: offset,
 		}
 	}
 }
 
+func (c *Config) updateLocals() {
+	for _, k := range c.listLocals() {
+		if _, ok := c.Locals.list[k]; !ok {
+			c.deleteLocal(k)
+		}
+	}
+
+	for k, v := range c.Locals.list {
+		c.getLocal(k).Key("port_offset").SetValue(fmt.Sprintf("%d", v.PortOffset))
+	}
+}
+
 func (c *Config) simplify() {
 	var mainSection = c.file.Section("")
-	var omitempty = []string{"next_version", "last_update_check"}
+	var omitempty = []string{
+		"next_version",
+		"last_update_check",
+		"local_memory",
+		"local_cpus",
+		"local_env",
+		"local_volumes",
+		"local_network",
+		"templates_dirs",
+		"registry_sources",
+	}
 
 	for _, k := range omitempty {
 		var key = mainSection.Key(k)
@@ -269,6 +459,10 @@ func (c *Config) simplifyRemotes() {
 			s.DeleteKey("url")
 		}
 
+		if p := s.Key("profile"); p.Value() == "" && p.Comment == "" {
+			s.DeleteKey("profile")
+		}
+
 		if len(s.Keys()) == 0 && s.Comment == "" {
 			c.deleteRemote(k)
 		}
@@ -288,6 +482,7 @@ func (c *Config) updateRemotes() {
 		key.SetValue(v.URL)
 		key.Comment = v.URLComment
 		s.Comment = v.Comment
+		s.Key("profile").SetValue(v.Profile)
 	}
 
 	c.simplifyRemotes()
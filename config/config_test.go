@@ -370,6 +370,181 @@ func TestRemotesListAndGet(t *testing.T) {
 	}
 }
 
+func TestLocals(t *testing.T) {
+	setenv("WEDEPLOY_CUSTOM_HOME", abs("./mocks/locals"))
+
+	if err := Setup(); err != nil {
+		panic(err)
+	}
+
+	var wantList = []string{"ci", "old", "staging"}
+
+	if got := Global.Locals.List(); !reflect.DeepEqual(got, wantList) {
+		t.Errorf("Wanted %v, got %v instead", wantList, got)
+	}
+
+	var staging, ok = Global.Locals.Get("staging")
+
+	if !ok || staging.PortOffset != 100 {
+		t.Errorf("Wanted staging port offset to be 100, got %v instead", staging)
+	}
+
+	if _, ok := Global.Locals.Get("missing"); ok {
+		t.Errorf("Expected missing local to not be found")
+	}
+
+	if offset := Global.Locals.NextPortOffset(); offset != 400 {
+		t.Errorf("Wanted next port offset to be 400, got %v instead", offset)
+	}
+
+	Global.Locals.Del("old")
+
+	if offset := Global.Locals.NextPortOffset(); offset != 200 {
+		t.Errorf("Wanted next port offset to be 200, got %v instead", offset)
+	}
+
+	Global.Locals.Set("qa", 200)
+
+	var tmp, err = ioutil.TempFile(os.TempDir(), "we")
+
+	if err != nil {
+		panic(err)
+	}
+
+	Global.Path = tmp.Name()
+
+	if err := Global.Save(); err != nil {
+		panic(err)
+	}
+
+	var got = tdata.FromFile(Global.Path)
+	var want = tdata.FromFile("./mocks/we-reference-locals.ini")
+
+	if got != want {
+		t.Errorf("Wanted created configuration to match we-reference-locals.ini, got %v", got)
+	}
+
+	if err = tmp.Close(); err != nil {
+		panic(err)
+	}
+
+	if err = os.Remove(tmp.Name()); err != nil {
+		panic(err)
+	}
+
+	unsetenv("WEDEPLOY_CUSTOM_HOME")
+	Teardown()
+}
+
+func TestTemplatesDirs(t *testing.T) {
+	setenv("WEDEPLOY_CUSTOM_HOME", abs("./mocks/templates"))
+
+	if err := Setup(); err != nil {
+		panic(err)
+	}
+
+	var want = []string{"/opt/we/templates", "/home/fool/templates"}
+
+	if !reflect.DeepEqual(Global.TemplatesDirs, want) {
+		t.Errorf("Wanted templates dirs %v, got %v instead", want, Global.TemplatesDirs)
+	}
+
+	unsetenv("WEDEPLOY_CUSTOM_HOME")
+	Teardown()
+}
+
+func TestRemotesProfile(t *testing.T) {
+	setenv("WEDEPLOY_CUSTOM_HOME", abs("./mocks/profiles"))
+
+	if err := Setup(); err != nil {
+		panic(err)
+	}
+
+	if staging, _ := Global.Remotes.Get("staging"); staging.Profile != "staging" {
+		t.Errorf("Wanted staging remote profile to be staging, got %v instead", staging.Profile)
+	}
+
+	if beta, _ := Global.Remotes.Get("beta"); beta.Profile != "" {
+		t.Errorf("Wanted beta remote to have no profile, got %v instead", beta.Profile)
+	}
+
+	Global.Remotes.Set("staging", "https://staging.example.com/")
+
+	if staging, _ := Global.Remotes.Get("staging"); staging.Profile != "staging" {
+		t.Errorf("Wanted profile to be kept when setting the URL, got %v instead", staging.Profile)
+	}
+
+	Global.Remotes.SetProfile("beta", "beta")
+
+	if beta, _ := Global.Remotes.Get("beta"); beta.Profile != "beta" || beta.URL != "http://beta.example.com/" {
+		t.Errorf("Wanted beta remote profile to be set, got %+v instead", beta)
+	}
+
+	unsetenv("WEDEPLOY_CUSTOM_HOME")
+	Teardown()
+}
+
+func TestLists(t *testing.T) {
+	setenv("WEDEPLOY_CUSTOM_HOME", abs("./mocks/lists"))
+
+	if err := Setup(); err != nil {
+		panic(err)
+	}
+
+	var wantEnv = []string{"JAVA_OPTS=-Xms1g,-Xmx2g", "DEBUG=true"}
+	var wantVolumes = []string{"/data:/opt/data:ro,z"}
+
+	if !reflect.DeepEqual(Global.LocalEnv, wantEnv) {
+		t.Errorf("Wanted local env %v, got %v instead", wantEnv, Global.LocalEnv)
+	}
+
+	if !reflect.DeepEqual(Global.LocalVolumes, wantVolumes) {
+		t.Errorf("Wanted local volumes %v, got %v instead", wantVolumes, Global.LocalVolumes)
+	}
+
+	var tmp, err = ioutil.TempFile(os.TempDir(), "we")
+
+	if err != nil {
+		panic(err)
+	}
+
+	Global.Path = tmp.Name()
+	Global.LocalVolumes = append(Global.LocalVolumes, "/logs:/var/log")
+
+	if err = Global.Save(); err != nil {
+		panic(err)
+	}
+
+	var saved = &Config{
+		Path: tmp.Name(),
+	}
+
+	if err = saved.Load(); err != nil {
+		panic(err)
+	}
+
+	if !reflect.DeepEqual(saved.LocalEnv, wantEnv) {
+		t.Errorf("Wanted saved local env %v, got %v instead", wantEnv, saved.LocalEnv)
+	}
+
+	wantVolumes = append(wantVolumes, "/logs:/var/log")
+
+	if !reflect.DeepEqual(saved.LocalVolumes, wantVolumes) {
+		t.Errorf("Wanted saved local volumes %v, got %v instead", wantVolumes, saved.LocalVolumes)
+	}
+
+	if err = tmp.Close(); err != nil {
+		panic(err)
+	}
+
+	if err = os.Remove(tmp.Name()); err != nil {
+		panic(err)
+	}
+
+	unsetenv("WEDEPLOY_CUSTOM_HOME")
+	Teardown()
+}
+
 func abs(path string) string {
 	var abs, err = filepath.Abs(path)
 
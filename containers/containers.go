@@ -7,13 +7,15 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/wedeploy/api-go"
 	"github.com/wedeploy/cli/apihelper"
-	"github.com/wedeploy/cli/defaults"
+	"github.com/wedeploy/cli/definition"
 	"github.com/wedeploy/cli/hooks"
+	"github.com/wedeploy/cli/overlay"
 	"github.com/wedeploy/cli/verbose"
 	"github.com/wedeploy/cli/verbosereq"
 )
@@ -30,6 +32,17 @@ type Container struct {
 	Hooks     *hooks.Hooks      `json:"hooks,omitempty"`
 	Env       map[string]string `json:"env,omitempty"`
 	Instances int               `json:"instances,omitempty"`
+	DependsOn []string          `json:"dependsOn,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+
+	// Unresolved has the variables referenced by Env that are not set
+	Unresolved []string `json:"-"`
+}
+
+// Instance of a container
+type Instance struct {
+	UID    string `json:"containerUid"`
+	Health string `json:"health,omitempty"`
 }
 
 // Register for the container structure
@@ -51,6 +64,9 @@ var (
 	// ErrInvalidContainerID happens when a container ID is invalid
 	ErrInvalidContainerID = errors.New("Invalid container ID")
 
+	// ErrInvalidInstances happens when a number of instances is invalid
+	ErrInvalidInstances = errors.New("Invalid number of instances")
+
 	outStream io.Writer = os.Stdout
 )
 
@@ -107,24 +123,23 @@ func Unlink(projectID, containerID string) error {
 	return apihelper.Validate(req, req.Delete())
 }
 
-// GetRegistry gets a list of container images
-func GetRegistry() (registry []Register, err error) {
-	var request = wedeploy.URL(defaults.Hub, "/registry.json")
-
-	err = apihelper.Validate(request, request.Get())
+// Read a container directory properties (defined by a container.json or container.yaml on it)
+// interpolating its environment variables (see EnvFile)
+func Read(path string) (*Container, error) {
+	var c, err = ReadRaw(path)
 
 	if err != nil {
-		return nil, err
+		return c, err
 	}
 
-	err = apihelper.DecodeJSON(request, &registry)
-
-	return registry, err
+	return c, c.interpolateEnv(path)
 }
 
-// Read a container directory properties (defined by a container.json on it)
-func Read(path string) (*Container, error) {
-	var content, err = ioutil.ReadFile(filepath.Join(path, "container.json"))
+// ReadRaw reads a container directory properties
+// without interpolating its environment variables
+// (the container.<profile>.json overlay of the active profile is merged over it)
+func ReadRaw(path string) (*Container, error) {
+	var content, err = overlay.Read(path, "container", overlay.Profile())
 	var data Container
 
 	if err != nil {
@@ -157,6 +172,87 @@ func Restart(projectID, containerID string) error {
 	return apihelper.Validate(req, req.Post())
 }
 
+// Instances gets the running instances of a container inside a project
+func Instances(projectID, containerID string) ([]Instance, error) {
+	var is []Instance
+	var err = apihelper.AuthGet("/projects/"+projectID+"/containers/"+containerID+"/instances", &is)
+	return is, err
+}
+
+// RestartInstance restarts a single instance of a container inside a project
+func RestartInstance(projectID, containerID, instanceUID string) error {
+	var req = apihelper.URL("/restart/container?projectId=" + projectID +
+		"&containerId=" + containerID + "&containerUid=" + instanceUID)
+
+	apihelper.Auth(req)
+	return apihelper.Validate(req, req.Post())
+}
+
+// Scale changes the number of instances of a container inside a project
+func Scale(projectID, containerID string, instances int) error {
+	var req = apihelper.URL("/scale/container")
+	apihelper.Auth(req)
+
+	req.Param("projectId", projectID)
+	req.Param("containerId", containerID)
+	req.Param("instances", strconv.Itoa(instances))
+
+	return apihelper.Validate(req, req.Post())
+}
+
+// ValidateInstances validates a number of instances for a container
+func ValidateInstances(projectID, containerID string, instances int) error {
+	if instances < 1 {
+		return ErrInvalidInstances
+	}
+
+	var req = apihelper.URL("/validators/containers/instances")
+	apihelper.Auth(req)
+
+	req.Param("projectId", projectID)
+	req.Param("containerId", containerID)
+	req.Param("value", strconv.Itoa(instances))
+
+	var err = req.Get()
+	verbosereq.Feedback(req)
+
+	if err == nil || err != wedeploy.ErrUnexpectedResponse {
+		return err
+	}
+
+	var errDoc apihelper.APIFault
+
+	if err = apihelper.DecodeJSON(req, &errDoc); err != nil {
+		return err
+	}
+
+	if errDoc.Has("invalidInstances") {
+		return ErrInvalidInstances
+	}
+
+	return errDoc
+}
+
+// SetInstances saves the number of instances on the container.json of a container
+// keeping the other fields as they are
+func SetInstances(path string, instances int) error {
+	return updateDefinition(path, func(d *definition.Document) {
+		d.Set("instances", instances)
+	})
+}
+
+// updateDefinition updates the container.json (or container.yaml) of a container
+// keeping the fields not changed by the update function as they are
+func updateDefinition(path string, update func(d *definition.Document)) error {
+	var file, err = definition.Find(path, "container")
+
+	if err != nil {
+		return readValidate(Container{}, err)
+	}
+
+	return definition.Edit(file, update)
+}
+
 // Validate container
 func Validate(projectID, containerID string) (err error) {
 	var req = apihelper.URL("/validators/containers/id")
@@ -209,7 +305,7 @@ func getListFromDirectory(dir string, files []os.FileInfo) ([]string, error) {
 			continue
 		}
 
-		var container, err = Read(filepath.Join(dir, file.Name()))
+		var container, err = ReadRaw(filepath.Join(dir, file.Name()))
 
 		if err == nil {
 			if cp, ok := idToPathMap[container.ID]; ok {
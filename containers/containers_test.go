@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/kylelemons/godebug/pretty"
 	"github.com/wedeploy/api-go/jsonlib"
 	"github.com/wedeploy/cli/apihelper"
+	"github.com/wedeploy/cli/config"
 	"github.com/wedeploy/cli/configmock"
 	"github.com/wedeploy/cli/servertest"
 	"github.com/wedeploy/cli/tdata"
@@ -25,11 +27,27 @@ var bufOutStream bytes.Buffer
 
 func TestMain(m *testing.M) {
 	var defaultOutStream = outStream
+	var defaultRegistryCachePath = registryCachePath
+	var cacheDir, err = ioutil.TempDir(os.TempDir(), "we-registry-cache")
+
+	if err != nil {
+		panic(err)
+	}
+
 	outStream = &bufOutStream
+	registryCachePath = func() string {
+		return filepath.Join(cacheDir, ".we_registry_cache")
+	}
 
 	ec := m.Run()
 
 	outStream = defaultOutStream
+	registryCachePath = defaultRegistryCachePath
+
+	if err = os.RemoveAll(cacheDir); err != nil {
+		panic(err)
+	}
+
 	os.Exit(ec)
 }
 
@@ -47,6 +65,20 @@ func TestGetListFromDirectory(t *testing.T) {
 	}
 }
 
+func TestGetListFromDirectoryInvalidEnvFile(t *testing.T) {
+	var containers, err = GetListFromDirectory("mocks/app-with-invalid-env-file")
+
+	if err != nil {
+		t.Errorf("Expected %v, got %v instead", nil, err)
+	}
+
+	var wantContainers = []string{"broken", "email"}
+
+	if !reflect.DeepEqual(containers, wantContainers) {
+		t.Errorf("Want %v, got %v instead", wantContainers, containers)
+	}
+}
+
 func TestGetListFromDirectoryDuplicateID(t *testing.T) {
 	var containers, err = GetListFromDirectory("mocks/project-with-duplicate-containers-ids")
 
@@ -302,6 +334,67 @@ func TestRestart(t *testing.T) {
 	configmock.Teardown()
 }
 
+func TestRestartInstance(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+	bufOutStream.Reset()
+
+	servertest.Mux.HandleFunc("/restart/container",
+		func(w http.ResponseWriter, r *http.Request) {
+			var p, err = url.ParseQuery(r.URL.RawQuery)
+
+			if err != nil {
+				panic(err)
+			}
+
+			if p.Get("projectId") != "foo" || p.Get("containerId") != "bar" ||
+				p.Get("containerUid") != "bar_abc" {
+				t.Errorf("Wrong query parameters, got %v", r.URL.RawQuery)
+			}
+
+			fmt.Fprintf(w, `"on"`)
+		})
+
+	if err := RestartInstance("foo", "bar", "bar_abc"); err != nil {
+		t.Errorf("Unexpected error on container instance restart: %v", err)
+	}
+
+	servertest.Teardown()
+	configmock.Teardown()
+}
+
+func TestInstances(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/projects/foo/containers/bar/instances",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			fmt.Fprintf(w, `[
+	{"containerUid": "bar_a", "health": "up"},
+	{"containerUid": "bar_b", "health": "down"}
+]`)
+		})
+
+	var instances, err = Instances("foo", "bar")
+
+	if err != nil {
+		t.Errorf("Unexpected error getting instances: %v", err)
+	}
+
+	var want = []Instance{
+		Instance{UID: "bar_a", Health: "up"},
+		Instance{UID: "bar_b", Health: "down"},
+	}
+
+	if !reflect.DeepEqual(instances, want) {
+		t.Errorf("Wanted instances %v, got %v instead", want, instances)
+	}
+
+	servertest.Teardown()
+	configmock.Teardown()
+}
+
 func TestUnlink(t *testing.T) {
 	servertest.Setup()
 	configmock.Setup()
@@ -449,3 +542,216 @@ func TestNormalizePathToUnix(t *testing.T) {
 		}
 	}
 }
+
+func TestScale(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/scale/container",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "POST" {
+				t.Errorf("Unexpected method %v", r.Method)
+			}
+
+			if r.FormValue("projectId") != "foo" ||
+				r.FormValue("containerId") != "bar" ||
+				r.FormValue("instances") != "3" {
+				t.Errorf("Wrong query parameters, got %v", r.URL.RawQuery)
+			}
+		})
+
+	if err := Scale("foo", "bar", 3); err != nil {
+		t.Errorf("Unexpected error on container scale: %v", err)
+	}
+
+	servertest.Teardown()
+	configmock.Teardown()
+}
+
+func TestValidateInstances(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/validators/containers/instances",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.FormValue("value") != "3" {
+				w.Header().Set("Content-type", "application/json; charset=UTF-8")
+				w.WriteHeader(400)
+				fmt.Fprintf(w, tdata.FromFile("mocks/container_invalid_instances_response.json"))
+			}
+		})
+
+	if err := ValidateInstances("foo", "bar", 3); err != nil {
+		t.Errorf("Wanted null error, got %v instead", err)
+	}
+
+	if err := ValidateInstances("foo", "bar", 100); err != ErrInvalidInstances {
+		t.Errorf("Wanted %v error, got %v instead", ErrInvalidInstances, err)
+	}
+
+	if err := ValidateInstances("foo", "bar", 0); err != ErrInvalidInstances {
+		t.Errorf("Wanted %v error, got %v instead", ErrInvalidInstances, err)
+	}
+
+	servertest.Teardown()
+	configmock.Teardown()
+}
+
+func TestSetInstances(t *testing.T) {
+	var dir, err = ioutil.TempDir(os.TempDir(), "we-scale")
+
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			panic(err)
+		}
+	}()
+
+	tdata.ToFile(dir+"/container.json", `{"id": "bar", "custom": {"x": 1}}`)
+
+	if err = SetInstances(dir, 4); err != nil {
+		t.Errorf("Unexpected error saving instances: %v", err)
+	}
+
+	var data map[string]interface{}
+
+	if err = json.Unmarshal([]byte(tdata.FromFile(dir+"/container.json")), &data); err != nil {
+		panic(err)
+	}
+
+	var want = map[string]interface{}{
+		"id":        "bar",
+		"instances": float64(4),
+		"custom": map[string]interface{}{
+			"x": float64(1),
+		},
+	}
+
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("Wanted container.json %v, got %v instead", want, data)
+	}
+
+	if err = SetInstances(dir+"/missing", 4); err != ErrContainerNotFound {
+		t.Errorf("Wanted %v error, got %v instead", ErrContainerNotFound, err)
+	}
+}
+
+func TestReadProfile(t *testing.T) {
+	configmock.Setup()
+	config.Context.Profile = "staging"
+
+	var c, err = Read("mocks/profiles/email")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if c.ID != "email" || c.Instances != 3 {
+		t.Errorf("Expected staging overlay to be merged, got %+v instead", c)
+	}
+
+	config.Context.Profile = ""
+
+	if c, err = Read("mocks/profiles/email"); err != nil || c.Instances != 1 {
+		t.Errorf("Expected base definition without profile, got %+v (%v) instead", c, err)
+	}
+
+	configmock.Teardown()
+}
+
+func TestReadYAML(t *testing.T) {
+	var c, err = Read("mocks/yaml-app")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if c.ID != "app" || c.Type != "wedeploy/node" || c.Instances != 2 {
+		t.Errorf("Expected YAML definition to be read, got %+v instead", c)
+	}
+
+	if c.Hooks == nil || c.Hooks.Build != "npm install\nnpm run build\n" {
+		t.Errorf("Expected multi-line build hook, got %+v instead", c.Hooks)
+	}
+}
+
+func TestSetInstancesKeepsOrder(t *testing.T) {
+	var dir, err = ioutil.TempDir(os.TempDir(), "we-scale")
+
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			panic(err)
+		}
+	}()
+
+	tdata.ToFile(dir+"/container.json", `{"type": "wedeploy/email", "id": "bar", "instances": 1}`)
+
+	if err = SetInstances(dir, 3); err != nil {
+		t.Errorf("Unexpected error saving instances: %v", err)
+	}
+
+	var want = `{
+    "type": "wedeploy/email",
+    "id": "bar",
+    "instances": 3
+}`
+
+	if got := tdata.FromFile(dir + "/container.json"); got != want {
+		t.Errorf("Wanted container.json %v, got %v instead", want, got)
+	}
+
+	if err = os.Remove(dir + "/container.json"); err != nil {
+		panic(err)
+	}
+
+	tdata.ToFile(dir+"/container.yaml", "type: wedeploy/email\nid: bar\n")
+
+	if err = SetInstances(dir, 2); err != nil {
+		t.Errorf("Unexpected error saving instances: %v", err)
+	}
+
+	if got := tdata.FromFile(dir + "/container.yaml"); got != "type: wedeploy/email\nid: bar\ninstances: 2\n" {
+		t.Errorf("Expected instances to be appended to container.yaml, got %v instead", got)
+	}
+}
+
+func TestSaveEnvCommentedYAML(t *testing.T) {
+	var dir, err = ioutil.TempDir(os.TempDir(), "we-containers-env")
+
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			panic(err)
+		}
+	}()
+
+	var content = tdata.FromFile("mocks/commented-yaml/container.yaml")
+	tdata.ToFile(dir+"/container.yaml", content)
+
+	err = SaveEnv(dir, "LOG_LEVEL", "debug")
+	var wantErr = "Can't save on container.yaml: its comments or formatting would be lost. Change it by hand."
+
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("Wanted error %v, got %v instead", wantErr, err)
+	}
+
+	if got := tdata.FromFile(dir + "/container.yaml"); got != content {
+		t.Errorf("Expected container.yaml to be kept as it was, got %v instead", got)
+	}
+
+	c, err := Read(dir)
+
+	if err != nil || c.Hooks == nil || c.Hooks.Build != "npm install\nnpm run build\n" {
+		t.Errorf("Expected container.yaml to be readable after failing to save, got %+v (%v) instead", c, err)
+	}
+}
@@ -0,0 +1,230 @@
+package containers
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/apihelper"
+	"github.com/wedeploy/cli/definition"
+	"github.com/wedeploy/cli/verbose"
+)
+
+// EnvFile is the file with the variables available for interpolating
+// the environment variables of the container.json on the same directory
+const EnvFile = ".env"
+
+// envReference matches ${VAR} and ${VAR:-default} ($${VAR} escapes it)
+var envReference = regexp.MustCompile(`\$?\$\{([a-zA-Z_][a-zA-Z0-9_]*)(:-([^}]*))?\}`)
+
+// lookupEnv gets a variable from the shell environment
+var lookupEnv = os.LookupEnv
+
+// ReadEnvFile reads the KEY=VALUE pairs of an environment file
+// lines starting with # are comments and values might be quoted
+func ReadEnvFile(file string) (map[string]string, error) {
+	var f, err = os.Open(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if ec := f.Close(); ec != nil {
+			verbose.Debug("Error closing " + file + ": " + ec.Error())
+		}
+	}()
+
+	var env = map[string]string{}
+	var scanner = bufio.NewScanner(f)
+
+	for n := 1; scanner.Scan(); n++ {
+		var line = strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var kv = strings.SplitN(strings.TrimPrefix(line, "export "), "=", 2)
+
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("Can't read %v: invalid line %d.", file, n)
+		}
+
+		env[strings.TrimSpace(kv[0])] = unquote(strings.TrimSpace(kv[1]))
+	}
+
+	return env, scanner.Err()
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 &&
+		(value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+// Interpolate replaces the ${VAR} and ${VAR:-default} references on a string
+// returning the names of the variables that are not set
+func Interpolate(s string, lookup func(string) (string, bool)) (string, []string) {
+	var unresolved = []string{}
+
+	var value = envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		if strings.HasPrefix(ref, "$$") {
+			return ref[1:]
+		}
+
+		var m = envReference.FindStringSubmatch(ref)
+		var v, ok = lookup(m[1])
+
+		switch {
+		case ok && v != "":
+			return v
+		case m[2] != "":
+			return m[3]
+		case !ok:
+			unresolved = append(unresolved, m[1])
+		}
+
+		return v
+	})
+
+	return value, unresolved
+}
+
+// interpolateEnv interpolates the environment variables of a container
+// with the shell environment and the .env file on its directory
+func (c *Container) interpolateEnv(path string) error {
+	if len(c.Env) == 0 {
+		return nil
+	}
+
+	var file = filepath.Join(path, EnvFile)
+	var envFile, err = ReadEnvFile(file)
+
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return errwrap.Wrapf("Can't read "+EnvFile+": {{err}}", err)
+	}
+
+	var lookup = func(key string) (string, bool) {
+		if v, ok := lookupEnv(key); ok {
+			return v, true
+		}
+
+		var v, ok = envFile[key]
+		return v, ok
+	}
+
+	var unresolved = map[string]bool{}
+
+	for k, v := range c.Env {
+		var value, missing = Interpolate(v, lookup)
+		c.Env[k] = value
+
+		for _, m := range missing {
+			unresolved[m] = true
+		}
+	}
+
+	c.Unresolved = []string{}
+
+	for u := range unresolved {
+		c.Unresolved = append(c.Unresolved, u)
+	}
+
+	sort.Strings(c.Unresolved)
+	return nil
+}
+
+// secretKeyParts are parts of the names of variables holding secrets
+var secretKeyParts = []string{
+	"PASSWORD",
+	"PASSWD",
+	"SECRET",
+	"TOKEN",
+	"KEY",
+	"PRIVATE",
+	"CREDENTIAL",
+	"AUTH",
+}
+
+// LooksSecret tells if an environment variable looks like holding a secret
+func LooksSecret(key string) bool {
+	var k = strings.ToUpper(key)
+
+	for _, p := range secretKeyParts {
+		if strings.Contains(k, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetEnv gets the environment variables of a linked container
+func GetEnv(projectID, containerID string) (map[string]string, error) {
+	var c, err = Get(projectID, containerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Env == nil {
+		c.Env = map[string]string{}
+	}
+
+	return c.Env, nil
+}
+
+// SetEnv sets an environment variable of a linked container
+func SetEnv(projectID, containerID, key, value string) error {
+	var req = apihelper.URL("/projects", projectID, "containers", containerID, "env", key)
+	apihelper.Auth(req)
+
+	var err = apihelper.SetBody(req, map[string]string{
+		"value": value,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return apihelper.Validate(req, req.Put())
+}
+
+// UnsetEnv removes an environment variable of a linked container
+func UnsetEnv(projectID, containerID, key string) error {
+	var req = apihelper.URL("/projects", projectID, "containers", containerID, "env", key)
+	apihelper.Auth(req)
+
+	return apihelper.Validate(req, req.Delete())
+}
+
+// SaveEnv saves an environment variable on the container.json of a container
+func SaveEnv(path, key, value string) error {
+	return updateDefinition(path, func(d *definition.Document) {
+		var env = d.Child("env")
+		env.Set(key, value)
+		d.Set("env", env)
+	})
+}
+
+// RemoveEnv removes an environment variable from the container.json of a container
+func RemoveEnv(path, key string) error {
+	return updateDefinition(path, func(d *definition.Document) {
+		if _, ok := d.Get("env"); ok {
+			var env = d.Child("env")
+			env.Delete(key)
+			d.Set("env", env)
+		}
+	})
+}
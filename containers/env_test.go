@@ -0,0 +1,248 @@
+package containers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wedeploy/cli/configmock"
+	"github.com/wedeploy/cli/servertest"
+	"github.com/wedeploy/cli/tdata"
+)
+
+func TestInterpolate(t *testing.T) {
+	var vars = map[string]string{
+		"USER":  "admin",
+		"EMPTY": "",
+	}
+
+	var lookup = func(key string) (string, bool) {
+		var v, ok = vars[key]
+		return v, ok
+	}
+
+	var cases = []struct {
+		in         string
+		want       string
+		unresolved []string
+	}{
+		{"plain", "plain", []string{}},
+		{"${USER}", "admin", []string{}},
+		{"user=${USER}!", "user=admin!", []string{}},
+		{"${MISSING}", "", []string{"MISSING"}},
+		{"${MISSING:-guest}", "guest", []string{}},
+		{"${EMPTY:-guest}", "guest", []string{}},
+		{"${EMPTY}", "", []string{}},
+		{"${USER:-guest}", "admin", []string{}},
+		{"$${USER}", "${USER}", []string{}},
+		{"$USER", "$USER", []string{}},
+		{"${A}${B}", "", []string{"A", "B"}},
+	}
+
+	for _, c := range cases {
+		var got, unresolved = Interpolate(c.in, lookup)
+
+		if got != c.want || !reflect.DeepEqual(unresolved, c.unresolved) {
+			t.Errorf("Wanted Interpolate(%q) = %q %v, got %q %v instead",
+				c.in, c.want, c.unresolved, got, unresolved)
+		}
+	}
+}
+
+func TestReadEnvFile(t *testing.T) {
+	var env, err = ReadEnvFile("mocks/interpolated/.env")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = map[string]string{
+		"EMAIL_USER":     "admin",
+		"EMAIL_PASSWORD": "secret password",
+		"EMAIL_FROM":     "from-env-file@example.com",
+	}
+
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("Wanted %v, got %v instead", want, env)
+	}
+}
+
+func TestReadInterpolated(t *testing.T) {
+	var defaultLookupEnv = lookupEnv
+
+	lookupEnv = func(key string) (string, bool) {
+		if key == "EMAIL_FROM" {
+			return "from-shell@example.com", true
+		}
+
+		return "", false
+	}
+
+	var c, err = Read("mocks/interpolated")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = map[string]string{
+		"FROM":    "from-shell@example.com",
+		"HOST":    "smtp.example.com",
+		"URL":     "https://admin:secret password@localhost",
+		"TOKEN":   "",
+		"LITERAL": "${EMAIL_FROM}",
+	}
+
+	if !reflect.DeepEqual(c.Env, want) {
+		t.Errorf("Wanted env %v, got %v instead", want, c.Env)
+	}
+
+	if !reflect.DeepEqual(c.Unresolved, []string{"EMAIL_TOKEN"}) {
+		t.Errorf("Wanted unresolved [EMAIL_TOKEN], got %v instead", c.Unresolved)
+	}
+
+	lookupEnv = defaultLookupEnv
+}
+
+func TestReadRaw(t *testing.T) {
+	var c, err = ReadRaw("mocks/interpolated")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if c.Env["FROM"] != "${EMAIL_FROM}" {
+		t.Errorf("Expected env not to be interpolated, got %v instead", c.Env)
+	}
+}
+
+func TestReadInvalidEnvFile(t *testing.T) {
+	var _, err = Read("mocks/invalid-env-file")
+
+	if err == nil || !strings.Contains(err.Error(), "invalid line 2") {
+		t.Errorf("Expected invalid line error, got %v instead", err)
+	}
+}
+
+func TestLooksSecret(t *testing.T) {
+	var cases = map[string]bool{
+		"DB_PASSWORD":    true,
+		"api_key":        true,
+		"GITHUB_TOKEN":   true,
+		"ClientSecret":   true,
+		"AUTH_PROVIDER":  true,
+		"PORT":           false,
+		"FROM":           false,
+		"ELASTIC_HOSTS":  false,
+		"LOG_VERBOSITY":  false,
+		"PRIVATE_SSH_ID": true,
+	}
+
+	for key, want := range cases {
+		if got := LooksSecret(key); got != want {
+			t.Errorf("Wanted LooksSecret(%v) = %v, got %v instead", key, want, got)
+		}
+	}
+}
+
+func TestGetEnv(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/projects/foo/containers/bar",
+		tdata.ServerJSONHandler(`{"id": "bar", "env": {"PORT": "80"}}`))
+
+	var env, err = GetEnv("foo", "bar")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if !reflect.DeepEqual(env, map[string]string{"PORT": "80"}) {
+		t.Errorf("Unexpected env %v", env)
+	}
+
+	servertest.Teardown()
+	configmock.Teardown()
+}
+
+func TestSetEnv(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/projects/foo/containers/bar/env/PORT",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "PUT" {
+				t.Errorf("Unexpected method %v", r.Method)
+			}
+
+			var body, err = ioutil.ReadAll(r.Body)
+
+			if err != nil {
+				panic(err)
+			}
+
+			if string(body) != `{"value":"8080"}` {
+				t.Errorf("Unexpected body %v", string(body))
+			}
+		})
+
+	if err := SetEnv("foo", "bar", "PORT", "8080"); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	servertest.Teardown()
+	configmock.Teardown()
+}
+
+func TestUnsetEnv(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/projects/foo/containers/bar/env/PORT",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "DELETE" {
+				t.Errorf("Unexpected method %v", r.Method)
+			}
+		})
+
+	if err := UnsetEnv("foo", "bar", "PORT"); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	servertest.Teardown()
+	configmock.Teardown()
+}
+
+func TestSaveAndRemoveEnv(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "we-containers-env")
+
+	if err != nil {
+		panic(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	tdata.ToFile(filepath.Join(dir, "container.json"), `{"id": "bar", "type": "wedeploy/nodejs"}`)
+
+	if err = SaveEnv(dir, "PORT", "8080"); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	c, err := ReadRaw(dir)
+
+	if err != nil || c.Env["PORT"] != "8080" || c.Type != "wedeploy/nodejs" {
+		t.Errorf("Expected env to be saved, got %+v (%v) instead", c, err)
+	}
+
+	if err = RemoveEnv(dir, "PORT"); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if c, err = ReadRaw(dir); err != nil || len(c.Env) != 0 {
+		t.Errorf("Expected env to be removed, got %+v (%v) instead", c, err)
+	}
+}
@@ -0,0 +1,220 @@
+package containers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/api-go"
+	"github.com/wedeploy/cli/apihelper"
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/defaults"
+	"github.com/wedeploy/cli/user"
+	"github.com/wedeploy/cli/verbose"
+)
+
+// RegistryTTL is how long a cached registry is used without revalidating it
+var RegistryTTL = 24 * time.Hour
+
+// RegistryCache of the container types registry sources
+type RegistryCache struct {
+	Path    string
+	Entries map[string]RegistryCacheEntry
+}
+
+// RegistryCacheEntry is a cached registry source
+type RegistryCacheEntry struct {
+	ETag         string     `json:"etag,omitempty"`
+	LastModified string     `json:"lastModified,omitempty"`
+	Fetched      time.Time  `json:"fetched"`
+	Registry     []Register `json:"registry"`
+}
+
+var registryCachePath = func() string {
+	return filepath.Join(user.GetHomeDir(), ".we_registry_cache")
+}
+
+// GetRegistry gets a list of container images
+// from the registry sources configured on ~/.we and the hub
+func GetRegistry() ([]Register, error) {
+	return getRegistry(false)
+}
+
+// GetOfflineRegistry is like GetRegistry, but only uses the cache
+// (and the registry sources that are files)
+func GetOfflineRegistry() ([]Register, error) {
+	return getRegistry(true)
+}
+
+// RegistrySources gets the registry sources (files or URLs)
+// configured on ~/.we followed by the hub
+func RegistrySources() []string {
+	var sources = []string{}
+
+	if config.Global != nil {
+		sources = append(sources, config.Global.RegistrySources...)
+	}
+
+	return append(sources, defaults.Hub+"/registry.json")
+}
+
+func getRegistry(offline bool) ([]Register, error) {
+	var cache = &RegistryCache{
+		Path: registryCachePath(),
+	}
+
+	if err := cache.Load(); err != nil {
+		return nil, err
+	}
+
+	var found = map[string]bool{}
+	var registry = []Register{}
+
+	for _, source := range RegistrySources() {
+		var rs, err = cache.get(source, offline)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// container types on the first sources hide the ones on the next
+		for _, r := range rs {
+			if !found[r.ID] {
+				found[r.ID] = true
+				registry = append(registry, r)
+			}
+		}
+	}
+
+	if !offline {
+		if err := cache.Save(); err != nil {
+			verbose.Debug(err.Error())
+		}
+	}
+
+	return registry, nil
+}
+
+// Load the cache (a missing cache file means nothing is cached)
+func (rc *RegistryCache) Load() error {
+	rc.Entries = map[string]RegistryCacheEntry{}
+
+	var content, err = ioutil.ReadFile(rc.Path)
+
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Can't read registry cache: {{err}}", err)
+	}
+
+	if err = json.Unmarshal(content, &rc.Entries); err != nil {
+		verbose.Debug("Ignoring invalid registry cache: " + err.Error())
+		rc.Entries = map[string]RegistryCacheEntry{}
+	}
+
+	return nil
+}
+
+// Save the cache
+func (rc *RegistryCache) Save() error {
+	var bin, err = json.MarshalIndent(rc.Entries, "", "    ")
+
+	if err == nil {
+		err = ioutil.WriteFile(rc.Path, bin, 0644)
+	}
+
+	if err != nil {
+		return errwrap.Wrapf("Can't save registry cache: {{err}}", err)
+	}
+
+	return nil
+}
+
+func (rc *RegistryCache) get(source string, offline bool) ([]Register, error) {
+	if !isRegistryURL(source) {
+		return readRegistryFile(source)
+	}
+
+	var entry, cached = rc.Entries[source]
+
+	switch {
+	case offline && !cached:
+		return nil, fmt.Errorf("Registry %v is not cached: it must be fetched once before working offline.", source)
+	case offline, cached && time.Since(entry.Fetched) < RegistryTTL:
+		return entry.Registry, nil
+	}
+
+	var err = rc.fetch(source, &entry)
+
+	if err != nil && cached {
+		verbose.Debug("Using cached registry " + source + ": " + err.Error())
+		return entry.Registry, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	rc.Entries[source] = entry
+	return entry.Registry, nil
+}
+
+// fetch the registry, revalidating the cached entry, if any
+func (rc *RegistryCache) fetch(source string, entry *RegistryCacheEntry) error {
+	var request = wedeploy.URL(source)
+
+	if entry.ETag != "" {
+		request.Headers.Set("If-None-Match", entry.ETag)
+	}
+
+	if entry.LastModified != "" {
+		request.Headers.Set("If-Modified-Since", entry.LastModified)
+	}
+
+	if err := apihelper.Validate(request, request.Get()); err != nil {
+		return err
+	}
+
+	entry.Fetched = time.Now()
+
+	if request.Response.StatusCode == 304 {
+		verbose.Debug("Registry " + source + " not modified.")
+		return nil
+	}
+
+	var registry []Register
+
+	if err := apihelper.DecodeJSON(request, &registry); err != nil {
+		return err
+	}
+
+	entry.Registry = registry
+	entry.ETag = request.Response.Header.Get("ETag")
+	entry.LastModified = request.Response.Header.Get("Last-Modified")
+	return nil
+}
+
+func isRegistryURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+func readRegistryFile(path string) ([]Register, error) {
+	var registry []Register
+	var content, err = ioutil.ReadFile(path)
+
+	if err == nil {
+		err = json.Unmarshal(content, &registry)
+	}
+
+	if err != nil {
+		return nil, errwrap.Wrapf("Can't read registry "+path+": {{err}}", err)
+	}
+
+	return registry, nil
+}
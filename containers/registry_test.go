@@ -0,0 +1,161 @@
+package containers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/configmock"
+	"github.com/wedeploy/cli/servertest"
+	"github.com/wedeploy/cli/tdata"
+)
+
+func removeRegistryCache() {
+	if err := os.Remove(registryCachePath()); err != nil && !os.IsNotExist(err) {
+		panic(err)
+	}
+}
+
+func TestRegistryCache(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+	removeRegistryCache()
+
+	var requests = 0
+
+	servertest.Mux.HandleFunc("/registry.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			fmt.Fprintf(w, tdata.FromFile("mocks/registry.json"))
+		})
+
+	if _, err := GetRegistry(); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	// cached registry is used while fresh
+	if _, err := GetRegistry(); err != nil || requests != 1 {
+		t.Errorf("Expected cached registry to be used, got %v requests (%v) instead", requests, err)
+	}
+
+	// stale registry is revalidated
+	var defaultRegistryTTL = RegistryTTL
+	RegistryTTL = 0
+
+	var registry, err = GetRegistry()
+
+	if err != nil || requests != 2 {
+		t.Errorf("Expected registry to be revalidated, got %v requests (%v) instead", requests, err)
+	}
+
+	if len(registry) != 7 {
+		t.Errorf("Expected not modified registry to have 7 images, got %v instead", len(registry))
+	}
+
+	RegistryTTL = defaultRegistryTTL
+	removeRegistryCache()
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestRegistryStaleCacheOnError(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+	removeRegistryCache()
+
+	var fail = false
+
+	servertest.Mux.HandleFunc("/registry.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			if fail {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			fmt.Fprintf(w, tdata.FromFile("mocks/registry.json"))
+		})
+
+	if _, err := GetRegistry(); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	fail = true
+	var defaultRegistryTTL = RegistryTTL
+	RegistryTTL = time.Duration(0)
+
+	if registry, err := GetRegistry(); err != nil || len(registry) != 7 {
+		t.Errorf("Expected stale cached registry to be used, got %v (%v) instead", registry, err)
+	}
+
+	RegistryTTL = defaultRegistryTTL
+	removeRegistryCache()
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestOfflineRegistry(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+	removeRegistryCache()
+
+	servertest.Mux.HandleFunc("/registry.json",
+		tdata.ServerJSONFileHandler("mocks/registry.json"))
+
+	if _, err := GetOfflineRegistry(); err == nil {
+		t.Errorf("Expected error getting uncached registry offline")
+	}
+
+	if _, err := GetRegistry(); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	servertest.Teardown()
+
+	if registry, err := GetOfflineRegistry(); err != nil || len(registry) != 7 {
+		t.Errorf("Expected cached registry offline, got %v (%v) instead", registry, err)
+	}
+
+	removeRegistryCache()
+	configmock.Teardown()
+}
+
+func TestRegistrySources(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+	removeRegistryCache()
+
+	config.Global.RegistrySources = []string{"mocks/private_registry.json"}
+
+	servertest.Mux.HandleFunc("/registry.json",
+		tdata.ServerJSONFileHandler("mocks/registry.json"))
+
+	var registry, err = GetRegistry()
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if len(registry) != 8 {
+		t.Errorf("Expected registry to have 8 images, got %v instead", len(registry))
+	}
+
+	if registry[0].ID != "billing" || registry[1].Name != "Company Email" {
+		t.Errorf("Expected private registry to come first, got %v instead", registry[:2])
+	}
+
+	removeRegistryCache()
+	configmock.Teardown()
+	servertest.Teardown()
+}
@@ -15,8 +15,14 @@ import (
 	"github.com/wedeploy/cli/color"
 	"github.com/wedeploy/cli/config"
 	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/definition"
+	"github.com/wedeploy/cli/picker"
 	"github.com/wedeploy/cli/projects"
 	"github.com/wedeploy/cli/prompt"
+	"github.com/wedeploy/cli/schema"
+	"github.com/wedeploy/cli/templates"
+	"github.com/wedeploy/cli/verbose"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 var (
@@ -34,10 +40,129 @@ var (
 
 	// ErrContainerAlreadyExists indicates that a container already exists
 	ErrContainerAlreadyExists = errors.New("Invalid path for new configuration: container already exists")
+
+	// ErrProjectEnv indicates environment variables were given for a project
+	ErrProjectEnv = errors.New("Environment variables can only be set on containers.")
+
+	// ErrMissingType indicates the container type is missing and can't be asked
+	ErrMissingType = errors.New("Missing container type: no terminal (/dev/tty) detected for asking it. Use --type.")
 )
 
+// Answers for creating a project or container without prompting
+type Answers struct {
+	ID           string            `json:"id,omitempty"`
+	Type         string            `json:"type,omitempty"`
+	Name         string            `json:"name,omitempty"`
+	CustomDomain string            `json:"customDomain,omitempty"`
+	Env          map[string]string `json:"env,omitempty"`
+	Template     string            `json:"template,omitempty"`
+	Offline      bool              `json:"offline,omitempty"`
+}
+
+// isTerminal tells if values missing on the answers can be prompted
+var isTerminal = func() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// ReadAnswers reads answers from a JSON file
+// unknown fields are rejected, so a typo doesn't go unnoticed
+func ReadAnswers(file string) (Answers, error) {
+	var answers Answers
+	var content, err = ioutil.ReadFile(file)
+
+	if err == nil {
+		err = validateAnswers(file, content)
+	}
+
+	if err == nil {
+		err = json.Unmarshal(content, &answers)
+	}
+
+	if err != nil {
+		return answers, errwrap.Wrapf("Can't read answers file: {{err}}", err)
+	}
+
+	return answers, nil
+}
+
+func validateAnswers(file string, content []byte) error {
+	if es := schema.Answers.Validate(file, content); len(es) != 0 {
+		return es
+	}
+
+	return nil
+}
+
+// Merge the answers with the given ones, which take precedence
+func (a Answers) Merge(o Answers) Answers {
+	if o.ID != "" {
+		a.ID = o.ID
+	}
+
+	if o.Type != "" {
+		a.Type = o.Type
+	}
+
+	if o.Name != "" {
+		a.Name = o.Name
+	}
+
+	if o.CustomDomain != "" {
+		a.CustomDomain = o.CustomDomain
+	}
+
+	if o.Template != "" {
+		a.Template = o.Template
+	}
+
+	if o.Offline {
+		a.Offline = true
+	}
+
+	if len(a.Env) == 0 && len(o.Env) == 0 {
+		return a
+	}
+
+	// copy the environment variables so the merged answers don't share them
+	var env = map[string]string{}
+
+	for k, v := range a.Env {
+		env[k] = v
+	}
+
+	for k, v := range o.Env {
+		env[k] = v
+	}
+
+	a.Env = env
+	return a
+}
+
+// ask prompts for a value unless it was answered or there is no terminal
+func ask(question, answer string) string {
+	if answer == "" && isTerminal() {
+		return prompt.Prompt(question)
+	}
+
+	return answer
+}
+
+// askRequired is like ask, but fails when the value can't be prompted
+func askRequired(question, answer string) (string, error) {
+	if answer == "" && !isTerminal() {
+		return "", fmt.Errorf("Missing value for %v: no terminal (/dev/tty) detected for asking it.",
+			strings.ToLower(question))
+	}
+
+	return ask(question, answer), nil
+}
+
 // New creates a resource
-func New(id, directory string) error {
+func New(id, directory string, answers Answers) error {
+	if id == "" {
+		id = answers.ID
+	}
+
 	if directory == "" {
 		directory = id
 	}
@@ -56,9 +181,9 @@ func New(id, directory string) error {
 
 	switch config.Context.Scope {
 	case "project":
-		return newContainer(id, directory)
+		return newContainer(id, directory, answers)
 	case "global":
-		return newProject(id, directory)
+		return newProject(id, directory, answers)
 	default:
 		return getErrContainerAlreadyExists(config.Context.ContainerRoot)
 	}
@@ -68,9 +193,10 @@ type containerCreator struct {
 	Container *containers.Container
 	Register  containers.Register
 	Directory string
+	Answers   Answers
 }
 
-func newContainer(id, directory string) error {
+func newContainer(id, directory string, answers Answers) error {
 	if config.Context.Scope == "container" {
 		return getErrContainerAlreadyExists(config.Context.ContainerRoot)
 	}
@@ -81,8 +207,10 @@ func newContainer(id, directory string) error {
 
 	return (&containerCreator{
 		Directory: directory,
+		Answers:   answers,
 		Container: &containers.Container{
-			ID: id,
+			ID:  id,
+			Env: answers.Env,
 		},
 	}).run()
 }
@@ -120,16 +248,116 @@ func (cc *containerCreator) run() error {
 		return err
 	}
 
+	if err = cc.scaffold(); err != nil {
+		return err
+	}
+
 	return cc.saveContainer()
 }
 
+// scaffold instantiates the container template (a template name, directory
+// or git repository URL), or the template for the container type, if any
+func (cc *containerCreator) scaffold() error {
+	var source = cc.Answers.Template
+	var tmpl, err = getTemplate(source, cc.Register.Type)
+
+	switch {
+	case err == templates.ErrTemplateNotFound && source == "":
+		verbose.Debug("No template for container type " + cc.Register.Type)
+		return nil
+	case err == templates.ErrTemplateNotFound:
+		return fmt.Errorf("Template %v not found.", source)
+	case err != nil:
+		return err
+	}
+
+	if templates.IsRepository(source) {
+		defer func() {
+			if er := os.RemoveAll(tmpl.Path); er != nil {
+				verbose.Debug("Error removing template directory: " + er.Error())
+			}
+		}()
+	}
+
+	project, err := projects.Read(config.Context.ProjectRoot)
+
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Instantiate(cc.Directory, templates.Data{
+		ID:        cc.Container.ID,
+		Name:      cc.Container.Name,
+		Type:      cc.Container.Type,
+		ProjectID: project.ID,
+	})
+}
+
+func getTemplate(source, containerType string) (templates.Template, error) {
+	switch {
+	case source == "":
+		return templates.Find(templates.Dirs(), containerType)
+	case templates.IsRepository(source):
+		return templates.Clone(source)
+	}
+
+	if info, err := os.Stat(source); err == nil && info.IsDir() {
+		return templates.Template{
+			Name: filepath.Base(source),
+			Path: source,
+		}, nil
+	}
+
+	return templates.Find(templates.Dirs(), source)
+}
+
 func (cc *containerCreator) getContainersRegister() error {
-	registry, err := containers.GetRegistry()
+	if cc.Answers.Type == "" && !isTerminal() {
+		return ErrMissingType
+	}
+
+	var getRegistry = containers.GetRegistry
+
+	if cc.Answers.Offline {
+		getRegistry = containers.GetOfflineRegistry
+	}
+
+	registry, err := getRegistry()
 
 	if err != nil {
 		return errwrap.Wrapf("Can't get the registry: {{err}}", err)
 	}
 
+	if cc.Answers.Type != "" {
+		return cc.getContainersRegisterByType(registry)
+	}
+
+	var items = []picker.Item{}
+
+	for _, r := range registry {
+		items = append(items, picker.Item{
+			Label:       fmt.Sprintf("%v (%v)", r.Name, r.Type),
+			Category:    r.Category,
+			Description: r.Description,
+		})
+	}
+
+	index, err := picker.Pick("Select container type:", items)
+
+	switch err {
+	case nil:
+		cc.Register = registry[index]
+		return nil
+	case picker.ErrNoRawMode:
+		return cc.selectContainersRegister(registry)
+	default:
+		return err
+	}
+}
+
+// selectContainersRegister asks for the container type from a numbered list
+// when the terminal doesn't support the interactive picker
+func (cc *containerCreator) selectContainersRegister(registry []containers.Register) error {
 	for pos, r := range registry {
 		ne := fmt.Sprintf("%d) %v", pos+1, r.Name)
 
@@ -148,17 +376,28 @@ func (cc *containerCreator) getContainersRegister() error {
 
 	index--
 
-	if err != nil || index < 0 || index > len(registry) {
+	if err != nil || index < 0 || index >= len(registry) {
 		return errors.New("Invalid option.")
 	}
 
 	cc.Register = registry[index]
-	return err
+	return nil
+}
+
+func (cc *containerCreator) getContainersRegisterByType(registry []containers.Register) error {
+	for _, r := range registry {
+		if r.Type == cc.Answers.Type || r.ID == cc.Answers.Type {
+			cc.Register = r
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Container type %v not found on the registry.", cc.Answers.Type)
 }
 
 func (cc *containerCreator) chooseContainerOptions() error {
 	if cc.Container.ID == "" {
-		cc.Container.ID = prompt.Prompt("Id [default: " + cc.Register.ID + "]")
+		cc.Container.ID = ask("Id [default: "+cc.Register.ID+"]", "")
 	}
 
 	if cc.Container.ID == "" {
@@ -175,7 +414,7 @@ func (cc *containerCreator) chooseContainerOptions() error {
 		return err
 	}
 
-	cc.Container.Name = prompt.Prompt("Name [default: " + cc.Register.Name + "]")
+	cc.Container.Name = ask("Name [default: "+cc.Register.Name+"]", cc.Answers.Name)
 
 	if cc.Container.Name == "" {
 		cc.Container.Name = cc.Register.Name
@@ -187,14 +426,25 @@ func (cc *containerCreator) chooseContainerOptions() error {
 }
 
 func (cc *containerCreator) saveContainer() error {
+	cc.mergeTemplateContainer()
+
 	var bin, err = json.MarshalIndent(cc.Container, "", "    ")
 
 	if err != nil {
 		return err
 	}
 
-	err = ioutil.WriteFile(filepath.Join(cc.Directory, "container.json"),
-		bin, 0644)
+	// keep the format of the container definition created by a template
+	file, err := definition.Find(cc.Directory, "container")
+
+	switch {
+	case os.IsNotExist(err):
+		file = filepath.Join(cc.Directory, "container.json")
+	case err != nil:
+		return err
+	}
+
+	err = definition.Write(file, bin)
 
 	if err == nil {
 		abs, ea := filepath.Abs(filepath.Join(cc.Directory))
@@ -209,18 +459,49 @@ func (cc *containerCreator) saveContainer() error {
 	return err
 }
 
-func newProject(id, directory string) error {
+// mergeTemplateContainer keeps the container.json created by a template
+// (i.e., with its hooks) using the chosen values for the container
+func (cc *containerCreator) mergeTemplateContainer() {
+	var c, err = containers.ReadRaw(cc.Directory)
+
+	if err != nil && err != containers.ErrInvalidContainerID {
+		return
+	}
+
+	c.ID = cc.Container.ID
+	c.Name = cc.Container.Name
+	c.Type = cc.Container.Type
+
+	for k, v := range cc.Container.Env {
+		if c.Env == nil {
+			c.Env = map[string]string{}
+		}
+
+		c.Env[k] = v
+	}
+
+	cc.Container = c
+}
+
+func newProject(id, directory string, answers Answers) (err error) {
 	if config.Context.Scope != "global" {
 		return ErrProjectPath
 	}
 
+	if len(answers.Env) != 0 {
+		return ErrProjectEnv
+	}
+
 	var p = &projects.Project{}
 
 	p.ID = id
 
-	if p.ID == "" {
+	if p.ID == "" && isTerminal() {
 		fmt.Println("Creating project:")
-		p.ID = prompt.Prompt("ID")
+	}
+
+	if p.ID, err = askRequired("ID", p.ID); err != nil {
+		return err
 	}
 
 	if p.ID == "" {
@@ -235,8 +516,8 @@ func newProject(id, directory string) error {
 		return err
 	}
 
-	p.Name = prompt.Prompt("Name")
-	p.CustomDomain = prompt.Prompt("Custom domain")
+	p.Name = ask("Name", answers.Name)
+	p.CustomDomain = ask("Custom domain", answers.CustomDomain)
 
 	return saveProject(p, directory)
 }
@@ -270,7 +551,7 @@ func tryProjectDirectory(directory string) error {
 		return errwrap.Wrapf("Can't create project directory: {{err}}", err)
 	}
 
-	_, err = os.Stat(filepath.Join(directory, "project.json"))
+	_, err = definition.Find(directory, "project")
 
 	abs, eabs := filepath.Abs(directory)
 
@@ -295,7 +576,7 @@ func tryContainerDirectory(directory string) error {
 		return errwrap.Wrapf("Can't create container directory: {{err}}", err)
 	}
 
-	_, err = os.Stat(filepath.Join(directory, "container.json"))
+	_, err = definition.Find(directory, "container")
 
 	abs, eabs := filepath.Abs(directory)
 
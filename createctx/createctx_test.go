@@ -0,0 +1,167 @@
+package createctx
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/configmock"
+)
+
+func TestMain(m *testing.M) {
+	var defaultIsTerminal = isTerminal
+	isTerminal = func() bool {
+		return false
+	}
+
+	ec := m.Run()
+	isTerminal = defaultIsTerminal
+	os.Exit(ec)
+}
+
+func TestReadAnswers(t *testing.T) {
+	var answers, err = ReadAnswers("mocks/answers.json")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = Answers{
+		ID:   "email",
+		Type: "wedeploy/email",
+		Name: "Email",
+		Env: map[string]string{
+			"FROM":      "noreply@example.com",
+			"LOG_LEVEL": "info",
+		},
+	}
+
+	if !reflect.DeepEqual(answers, want) {
+		t.Errorf("Wanted answers %+v, got %+v instead", want, answers)
+	}
+}
+
+func TestReadAnswersInvalid(t *testing.T) {
+	for _, file := range []string{"mocks/invalid-answers.json", "mocks/not-found.json"} {
+		if _, err := ReadAnswers(file); err == nil {
+			t.Errorf("Expected error reading %v, got nil instead", file)
+		}
+	}
+}
+
+func TestReadAnswersUnknownField(t *testing.T) {
+	var _, err = ReadAnswers("mocks/typo-answers.json")
+	var want = "Can't read answers file: List of errors (format is file:line: error)\n" +
+		"mocks/typo-answers.json:3: Unknown field tpye."
+
+	if err == nil || err.Error() != want {
+		t.Errorf("Wanted error %v, got %v instead", want, err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var file, err = ReadAnswers("mocks/answers.json")
+
+	if err != nil {
+		panic(err)
+	}
+
+	var flags = Answers{
+		Type: "wedeploy/email:2.0",
+		Env: map[string]string{
+			"LOG_LEVEL": "debug",
+			"PORT":      "8080",
+		},
+	}
+
+	var want = Answers{
+		ID:   "email",
+		Type: "wedeploy/email:2.0",
+		Name: "Email",
+		Env: map[string]string{
+			"FROM":      "noreply@example.com",
+			"LOG_LEVEL": "debug",
+			"PORT":      "8080",
+		},
+	}
+
+	if got := file.Merge(flags); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted merged answers %+v, got %+v instead", want, got)
+	}
+
+	if file.Env["LOG_LEVEL"] != "info" || len(file.Env) != 2 {
+		t.Errorf("Expected answers file env to be kept, got %v instead", file.Env)
+	}
+}
+
+func TestMergeNoEnv(t *testing.T) {
+	var got = Answers{ID: "email"}.Merge(Answers{Offline: true})
+
+	if !reflect.DeepEqual(got, Answers{ID: "email", Offline: true}) {
+		t.Errorf("Expected answers without env, got %+v instead", got)
+	}
+}
+
+func TestNewProjectEnv(t *testing.T) {
+	configmock.Setup()
+	config.Context.Scope = "global"
+
+	var err = New("shop", "", Answers{
+		Env: map[string]string{
+			"FROM": "noreply@example.com",
+		},
+	})
+
+	if err != ErrProjectEnv {
+		t.Errorf("Wanted error %v, got %v instead", ErrProjectEnv, err)
+	}
+
+	configmock.Teardown()
+}
+
+func TestNewProjectMissingIDNoTerminal(t *testing.T) {
+	configmock.Setup()
+	config.Context.Scope = "global"
+
+	var err = New("", "", Answers{})
+	var want = "Missing value for id: no terminal (/dev/tty) detected for asking it."
+
+	if err == nil || err.Error() != want {
+		t.Errorf("Wanted error %v, got %v instead", want, err)
+	}
+
+	configmock.Teardown()
+}
+
+func TestNewContainerMissingTypeNoTerminal(t *testing.T) {
+	var tmp, err = ioutil.TempDir("", "we-createctx")
+
+	if err != nil {
+		panic(err)
+	}
+
+	var wd string
+
+	if wd, err = os.Getwd(); err != nil {
+		panic(err)
+	}
+
+	configmock.Setup()
+	config.Context.Scope = "project"
+	config.Context.ProjectRoot = wd
+
+	err = New("email", filepath.Join(tmp, "email"), Answers{})
+
+	if err != ErrMissingType {
+		t.Errorf("Wanted error %v, got %v instead", ErrMissingType, err)
+	}
+
+	configmock.Teardown()
+
+	if err = os.RemoveAll(tmp); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,307 @@
+package definition
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"gopkg.in/yaml.v2"
+)
+
+// Formats of the definition files
+const (
+	JSON = "json"
+	YAML = "yaml"
+)
+
+// Formats supported, in order of precedence
+var Formats = []string{JSON, YAML}
+
+// MultipleFormatsError is used when a definition exists in more than one format
+type MultipleFormatsError struct {
+	Dir   string
+	Name  string
+	Files []string
+}
+
+func (m MultipleFormatsError) Error() string {
+	var dir = m.Dir
+
+	if dir == "" {
+		dir = "."
+	}
+
+	return fmt.Sprintf("Found %v on %v: keep only one of them.",
+		strings.Join(m.Files, " and "), dir)
+}
+
+// Find the file of a definition (i.e., container for container.json or container.yaml)
+// the error satisfies os.IsNotExist when the definition doesn't exist
+func Find(dir, name string) (string, error) {
+	var found = []string{}
+
+	for _, format := range Formats {
+		var file = name + "." + format
+		var stat, err = os.Stat(filepath.Join(dir, file))
+
+		switch {
+		case err == nil && !stat.IsDir():
+			found = append(found, file)
+		case err != nil && !os.IsNotExist(err):
+			return "", err
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", os.ErrNotExist
+	case 1:
+		return filepath.Join(dir, found[0]), nil
+	default:
+		return "", MultipleFormatsError{
+			Dir:   dir,
+			Name:  name,
+			Files: found,
+		}
+	}
+}
+
+// Exists tells if a definition exists on a directory in any format
+func Exists(dir, name string) bool {
+	var _, err = Find(dir, name)
+	return !os.IsNotExist(err)
+}
+
+// Format of a definition file
+func Format(file string) string {
+	if ext := filepath.Ext(file); ext == ".yaml" {
+		return YAML
+	}
+
+	return JSON
+}
+
+// Read a definition file as JSON (YAML files are converted)
+func Read(file string) ([]byte, error) {
+	var content, err = ioutil.ReadFile(file)
+
+	if err != nil || Format(file) == JSON {
+		return content, err
+	}
+
+	if content, err = ToJSON(content); err != nil {
+		return nil, errwrap.Wrapf("Can't read "+filepath.Base(file)+": {{err}}", err)
+	}
+
+	return content, nil
+}
+
+// Write a JSON definition to a file (converting it to the format of the file)
+func Write(file string, content []byte) (err error) {
+	if Format(file) == YAML {
+		if content, err = ToYAML(content); err != nil {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(file, content, 0644)
+}
+
+// ToYAML converts a JSON document to YAML keeping the order of the fields
+func ToYAML(content []byte) ([]byte, error) {
+	// JSON is valid YAML, so decoding to a map slice keeps the order of the fields
+	var m yaml.MapSlice
+
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(m)
+}
+
+// ToJSON converts a YAML document to JSON keeping the order of the fields
+func ToJSON(content []byte) ([]byte, error) {
+	var m yaml.MapSlice
+
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(toJSONValue(m), "", "    ")
+}
+
+// orderedMap is encoded as a JSON object keeping the order of its fields
+type orderedMap yaml.MapSlice
+
+func (o orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+
+	b.WriteString("{")
+
+	for i, item := range o {
+		if i != 0 {
+			b.WriteString(",")
+		}
+
+		var key, err = json.Marshal(fmt.Sprint(item.Key))
+
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(toJSONValue(item.Value))
+
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(key)
+		b.WriteString(":")
+		b.Write(value)
+	}
+
+	b.WriteString("}")
+	return b.Bytes(), nil
+}
+
+func toJSONValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case yaml.MapSlice:
+		return orderedMap(value)
+	case map[interface{}]interface{}:
+		var m = map[string]interface{}{}
+
+		for k, item := range value {
+			m[fmt.Sprint(k)] = toJSONValue(item)
+		}
+
+		return m
+	case []interface{}:
+		var list = make([]interface{}, len(value))
+
+		for i, item := range value {
+			list[i] = toJSONValue(item)
+		}
+
+		return list
+	default:
+		return v
+	}
+}
+
+// Convert a definition file to another format, removing the original file
+// it returns the path of the converted file
+func Convert(file, format string) (string, error) {
+	var converted = strings.TrimSuffix(file, filepath.Ext(file)) + "." + format
+
+	if _, err := os.Stat(converted); err == nil {
+		return "", fmt.Errorf("Can't convert %v: %v already exists.",
+			file, filepath.Base(converted))
+	}
+
+	var content, err = Read(file)
+
+	if err != nil {
+		return "", err
+	}
+
+	if err = Write(converted, content); err != nil {
+		return "", errwrap.Wrapf("Can't write "+filepath.Base(converted)+": {{err}}", err)
+	}
+
+	return converted, os.Remove(file)
+}
+
+// Document is a definition whose fields keep their order when edited
+type Document yaml.MapSlice
+
+// Get the value of a field
+func (d Document) Get(key string) (interface{}, bool) {
+	for _, item := range d {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// Set the value of a field, appending it when it doesn't exist
+func (d *Document) Set(key string, value interface{}) {
+	if doc, ok := value.(Document); ok {
+		value = yaml.MapSlice(doc)
+	}
+
+	for i, item := range *d {
+		if item.Key == key {
+			(*d)[i].Value = value
+			return
+		}
+	}
+
+	*d = append(*d, yaml.MapItem{Key: key, Value: value})
+}
+
+// Delete a field
+func (d *Document) Delete(key string) {
+	for i, item := range *d {
+		if item.Key == key {
+			*d = append((*d)[:i], (*d)[i+1:]...)
+			return
+		}
+	}
+}
+
+// Child gets the object of a field (empty if the field is not an object)
+func (d Document) Child(key string) Document {
+	var value, _ = d.Get(key)
+	var child, _ = value.(yaml.MapSlice)
+	return Document(child)
+}
+
+// Edit a definition file keeping the order of its fields
+// YAML files are only edited when they can be written back without losing
+// their comments or formatting (such as block scalars)
+func Edit(file string, edit func(d *Document)) error {
+	var content, err = ioutil.ReadFile(file)
+
+	if err != nil {
+		return err
+	}
+
+	var d Document
+
+	if err = yaml.Unmarshal(content, (*yaml.MapSlice)(&d)); err != nil {
+		return errwrap.Wrapf("Can't read "+filepath.Base(file)+": {{err}}", err)
+	}
+
+	if Format(file) == YAML && !isLosslessYAML(content, d) {
+		return fmt.Errorf("Can't save on %v: its comments or formatting would be lost. Change it by hand.",
+			filepath.Base(file))
+	}
+
+	edit(&d)
+
+	if Format(file) == YAML {
+		content, err = yaml.Marshal(yaml.MapSlice(d))
+	} else {
+		content, err = json.MarshalIndent(orderedMap(d), "", "    ")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, content, 0644)
+}
+
+// isLosslessYAML tells if a YAML document is written back the same way
+func isLosslessYAML(content []byte, d Document) bool {
+	var out, err = yaml.Marshal(yaml.MapSlice(d))
+	return err == nil && bytes.Equal(bytes.TrimSpace(out), bytes.TrimSpace(content))
+}
@@ -0,0 +1,305 @@
+package definition
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestFind(t *testing.T) {
+	var cases = map[string]string{
+		"mocks/json": "mocks/json/container.json",
+		"mocks/yaml": "mocks/yaml/container.yaml",
+	}
+
+	for dir, want := range cases {
+		var file, err = Find(dir, "container")
+
+		if err != nil {
+			t.Errorf("Expected no error, got %v instead", err)
+		}
+
+		if file != want {
+			t.Errorf("Wanted file %v, got %v instead", want, file)
+		}
+	}
+}
+
+func TestFindNotExist(t *testing.T) {
+	var _, err = Find("mocks/none", "container")
+
+	if !os.IsNotExist(err) {
+		t.Errorf("Expected not exist error, got %v instead", err)
+	}
+
+	if Exists("mocks/none", "container") {
+		t.Errorf("Expected definition to not exist")
+	}
+}
+
+func TestFindMultipleFormats(t *testing.T) {
+	var _, err = Find("mocks/both", "container")
+
+	var want = MultipleFormatsError{
+		Dir:   "mocks/both",
+		Name:  "container",
+		Files: []string{"container.json", "container.yaml"},
+	}
+
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("Wanted error %v, got %v instead", want, err)
+	}
+
+	var wantMsg = "Found container.json and container.yaml on mocks/both: keep only one of them."
+
+	if err.Error() != wantMsg {
+		t.Errorf("Wanted error message %v, got %v instead", wantMsg, err)
+	}
+
+	if !Exists("mocks/both", "container") {
+		t.Errorf("Expected definition to exist")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if f := Format("container.yaml"); f != YAML {
+		t.Errorf("Expected yaml format, got %v instead", f)
+	}
+
+	if f := Format("container.json"); f != JSON {
+		t.Errorf("Expected json format, got %v instead", f)
+	}
+}
+
+func TestReadYAML(t *testing.T) {
+	var content, err = Read("mocks/yaml/container.yaml")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = `{
+    "id": "email",
+    "type": "wedeploy/email",
+    "env": {
+        "PORT": "8080"
+    },
+    "hooks": {
+        "build": "npm install\nnpm run build\n"
+    }
+}`
+
+	if string(content) != want {
+		t.Errorf("Wanted content %v, got %v instead", want, string(content))
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	var content, err = Read("mocks/json/container.json")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want, _ = ioutil.ReadFile("mocks/json/container.json")
+
+	if string(content) != string(want) {
+		t.Errorf("Wanted content %v, got %v instead", string(want), string(content))
+	}
+}
+
+func TestReadInvalidYAML(t *testing.T) {
+	var tmp = createTempDir(t)
+	defer removeTempDir(t, tmp)
+
+	var file = filepath.Join(tmp, "container.yaml")
+
+	if err := ioutil.WriteFile(file, []byte("id: [email"), 0644); err != nil {
+		panic(err)
+	}
+
+	if _, err := Read(file); err == nil {
+		t.Errorf("Expected error, got nil instead")
+	}
+}
+
+func TestToYAML(t *testing.T) {
+	var content, err = ToYAML([]byte(`{"type": "wedeploy/email", "id": "email", "instances": 2}`))
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = "type: wedeploy/email\nid: email\ninstances: 2\n"
+
+	if string(content) != want {
+		t.Errorf("Wanted content %v, got %v instead", want, string(content))
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	var content, err = ToJSON([]byte("type: wedeploy/email\nid: email\ninstances: 2\n"))
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = `{
+    "type": "wedeploy/email",
+    "id": "email",
+    "instances": 2
+}`
+
+	if string(content) != want {
+		t.Errorf("Wanted content %v, got %v instead", want, string(content))
+	}
+}
+
+func TestConvert(t *testing.T) {
+	var tmp = createTempDir(t)
+	defer removeTempDir(t, tmp)
+
+	var file = filepath.Join(tmp, "container.json")
+	var content, _ = ioutil.ReadFile("mocks/json/container.json")
+
+	if err := ioutil.WriteFile(file, content, 0644); err != nil {
+		panic(err)
+	}
+
+	var converted, err = Convert(file, YAML)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if converted != filepath.Join(tmp, "container.yaml") {
+		t.Errorf("Expected file to be converted to container.yaml, got %v instead", converted)
+	}
+
+	if _, err = os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("Expected container.json to be removed, got %v instead", err)
+	}
+
+	got, err := ioutil.ReadFile(converted)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = "id: email\ntype: wedeploy/email\n"
+
+	if string(got) != want {
+		t.Errorf("Wanted content %v, got %v instead", want, string(got))
+	}
+
+	if converted, err = Convert(converted, JSON); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if got, _ = ioutil.ReadFile(converted); string(got) != string(content[:len(content)-1]) {
+		t.Errorf("Wanted content %v, got %v instead", string(content), string(got))
+	}
+}
+
+func TestConvertAlreadyExists(t *testing.T) {
+	var _, err = Convert("mocks/both/container.json", YAML)
+	var want = "Can't convert mocks/both/container.json: container.yaml already exists."
+
+	if err == nil || err.Error() != want {
+		t.Errorf("Wanted error %v, got %v instead", want, err)
+	}
+}
+
+func TestDocument(t *testing.T) {
+	var d Document
+	d.Set("id", "email")
+	d.Set("instances", 1)
+
+	var env = d.Child("env")
+	env.Set("FROM", "noreply@example.com")
+	env.Set("PORT", "25")
+	d.Set("env", env)
+	d.Set("instances", 2)
+
+	env = d.Child("env")
+	env.Delete("FROM")
+	d.Set("env", env)
+
+	var content, err = ToJSON(mustMarshalYAML(d))
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = `{
+    "id": "email",
+    "instances": 2,
+    "env": {
+        "PORT": "25"
+    }
+}`
+
+	if string(content) != want {
+		t.Errorf("Wanted document %v, got %v instead", want, string(content))
+	}
+
+	if _, ok := d.Get("missing"); ok {
+		t.Errorf("Expected missing field to not be found")
+	}
+}
+
+func TestEditYAMLWithComments(t *testing.T) {
+	var tmp = createTempDir(t)
+	defer removeTempDir(t, tmp)
+
+	var file = filepath.Join(tmp, "container.yaml")
+	var content, _ = ioutil.ReadFile("mocks/yaml/container.yaml")
+
+	if err := ioutil.WriteFile(file, content, 0644); err != nil {
+		panic(err)
+	}
+
+	var err = Edit(file, func(d *Document) {
+		d.Set("instances", 2)
+	})
+
+	var want = "Can't save on container.yaml: its comments or formatting would be lost. Change it by hand."
+
+	if err == nil || err.Error() != want {
+		t.Errorf("Wanted error %v, got %v instead", want, err)
+	}
+
+	if got, _ := ioutil.ReadFile(file); string(got) != string(content) {
+		t.Errorf("Expected file to be kept as it was, got %v instead", string(got))
+	}
+}
+
+func mustMarshalYAML(d Document) []byte {
+	var content, err = yaml.Marshal(yaml.MapSlice(d))
+
+	if err != nil {
+		panic(err)
+	}
+
+	return content
+}
+
+func createTempDir(t *testing.T) string {
+	var tmp, err = ioutil.TempDir("", "we-definition")
+
+	if err != nil {
+		t.Fatalf("Can't create temporary directory: %v", err)
+	}
+
+	return tmp
+}
+
+func removeTempDir(t *testing.T, tmp string) {
+	if err := os.RemoveAll(tmp); err != nil {
+		t.Errorf("Can't remove temporary directory: %v", err)
+	}
+}
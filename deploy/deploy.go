@@ -0,0 +1,230 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"time"
+
+	"github.com/henvic/uilive"
+	"github.com/wedeploy/cli/apihelper"
+	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/hooks"
+	"github.com/wedeploy/cli/list"
+	"github.com/wedeploy/cli/verbose"
+)
+
+// Deploy of a container
+type Deploy struct {
+	ProjectID     string
+	ContainerPath string
+	Container     *containers.Container
+	livew         *uilive.Writer
+}
+
+// New deploy for the container on a given directory
+func New(projectID, containerPath string) (*Deploy, error) {
+	var c, err = containers.Read(containerPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Deploy{
+		ProjectID:     projectID,
+		ContainerPath: containerPath,
+		Container:     c,
+	}, nil
+}
+
+// Run the deploy hooks, package the container directory and upload it
+// the hooks run in order: before_deploy, deploy, upload, after_deploy
+func (d *Deploy) Run() error {
+	d.livew = uilive.New()
+	return d.Container.Hooks.RunPhase(hooks.Deploy, d.ContainerPath, d.packAndUpload)
+}
+
+func (d *Deploy) packAndUpload() error {
+	var archive, files, err = d.pack()
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if er := os.Remove(archive.Name()); er != nil {
+			verbose.Debug("Error removing deploy archive:", er)
+		}
+	}()
+
+	return d.upload(archive, files)
+}
+
+func (d *Deploy) pack() (archive *os.File, files int, err error) {
+	if archive, err = ioutil.TempFile(os.TempDir(), "we-deploy"); err != nil {
+		return nil, 0, err
+	}
+
+	files, err = Pack(d.ContainerPath, archive)
+
+	if err == nil {
+		_, err = archive.Seek(0, 0)
+	}
+
+	if err != nil {
+		d.closeArchive(archive)
+		return nil, files, err
+	}
+
+	verbose.Debug(fmt.Sprintf("Container %v packaged with %d files", d.Container.ID, files))
+	return archive, files, nil
+}
+
+func (d *Deploy) closeArchive(archive *os.File) {
+	if err := archive.Close(); err != nil {
+		verbose.Debug("Error closing deploy archive:", err)
+	}
+
+	if err := os.Remove(archive.Name()); err != nil {
+		verbose.Debug("Error removing deploy archive:", err)
+	}
+}
+
+func (d *Deploy) upload(archive *os.File, files int) error {
+	var info, err = archive.Stat()
+
+	if err != nil {
+		return err
+	}
+
+	var req = apihelper.URL("/deploy")
+	apihelper.Auth(req)
+
+	req.Param("projectId", d.ProjectID)
+	req.Param("containerId", d.Container.ID)
+
+	var pr, pw = io.Pipe()
+	var mw = multipart.NewWriter(pw)
+	var progress = &progressReader{
+		reader:   archive,
+		total:    info.Size(),
+		interval: 100 * time.Millisecond,
+		render: func(current, total int64) {
+			d.renderProgress(files, current, total)
+		},
+	}
+
+	go func() {
+		var err = d.writeBody(mw, progress)
+
+		if ec := archive.Close(); err == nil {
+			err = ec
+		}
+
+		_ = pw.CloseWithError(err)
+	}()
+
+	req.Headers.Set("Content-Type", mw.FormDataContentType())
+	req.Body(pr)
+
+	return apihelper.Validate(req, req.Put())
+}
+
+func (d *Deploy) writeBody(mw *multipart.Writer, source io.Reader) error {
+	var cw, err = mw.CreateFormField("container")
+
+	if err != nil {
+		return err
+	}
+
+	if err = json.NewEncoder(cw).Encode(d.Container); err != nil {
+		return err
+	}
+
+	fw, err := mw.CreateFormFile("source", d.Container.ID+".tar.gz")
+
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(fw, source); err != nil {
+		return err
+	}
+
+	return mw.Close()
+}
+
+func (d *Deploy) renderProgress(files int, current, total int64) {
+	fmt.Fprintf(d.livew, "Uploading %v (%d files) %d%%\n",
+		d.Container.ID, files, getPercent(current, total))
+
+	if err := d.livew.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+}
+
+func getPercent(current, total int64) int64 {
+	if total == 0 {
+		return 100
+	}
+
+	return current * 100 / total
+}
+
+// progressReader reports the progress of reading from a reader
+// at most once every interval (and always when it ends)
+type progressReader struct {
+	reader   io.Reader
+	current  int64
+	total    int64
+	last     time.Time
+	interval time.Duration
+	render   func(current, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (n int, err error) {
+	n, err = p.reader.Read(b)
+	p.current += int64(n)
+
+	if err == io.EOF || p.current == p.total || time.Since(p.last) >= p.interval {
+		p.last = time.Now()
+		p.render(p.current, p.total)
+	}
+
+	return n, err
+}
+
+// Watch the status of the deployed containers until they are up
+func Watch(projectID string, containerIDs []string) {
+	var l = list.New(list.Filter{
+		Project:    projectID,
+		Containers: containerIDs,
+	})
+
+	var w = list.NewWatcher(l)
+
+	w.StopCondition = func() bool {
+		return containersUp(l, containerIDs)
+	}
+
+	w.Start()
+}
+
+func containersUp(l *list.List, containerIDs []string) bool {
+	if len(l.Projects) == 0 {
+		return false
+	}
+
+	for _, id := range containerIDs {
+		c, ok := l.Projects[0].Containers[id]
+
+		if !ok || c.Health != "up" {
+			return false
+		}
+	}
+
+	return true
+}
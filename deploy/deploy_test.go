@@ -0,0 +1,85 @@
+package deploy
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/wedeploy/cli/configmock"
+	"github.com/wedeploy/cli/servertest"
+)
+
+func TestDeploy(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	var called bool
+
+	servertest.Mux.HandleFunc("/deploy", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+
+		if r.Method != "PUT" {
+			t.Errorf("Expected deploy method to be PUT, got %v instead", r.Method)
+		}
+
+		var qp = r.URL.Query()
+
+		if qp.Get("projectId") != "myproject" || qp.Get("containerId") != "web" {
+			t.Errorf("Unexpected query params %v", qp)
+		}
+
+		var data map[string]string
+
+		if err := json.Unmarshal([]byte(r.FormValue("container")), &data); err != nil {
+			t.Errorf("Expected container definition, got %v instead", err)
+		}
+
+		if data["id"] != "web" || data["type"] != "wedeploy/hosting" {
+			t.Errorf("Unexpected container definition %v", data)
+		}
+
+		var source, header, err = r.FormFile("source")
+
+		if err != nil {
+			t.Fatalf("Expected source archive, got %v instead", err)
+		}
+
+		if header.Filename != "web.tar.gz" {
+			t.Errorf("Expected source archive to be web.tar.gz, got %v instead", header.Filename)
+		}
+
+		var want = []string{"container.json", "index.html", "logs/", "src/", "src/style.css"}
+
+		if got := listArchive(source); !reflect.DeepEqual(got, want) {
+			t.Errorf("Wanted archive to have %v, got %v instead", want, got)
+		}
+	})
+
+	var d, err = New("myproject", "mocks/myproject/web")
+
+	if err != nil {
+		panic(err)
+	}
+
+	if err = d.Run(); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if !called {
+		t.Errorf("Expected deploy endpoint to be called")
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestGetPercent(t *testing.T) {
+	if p := getPercent(50, 200); p != 25 {
+		t.Errorf("Expected 25%%, got %v instead", p)
+	}
+
+	if p := getPercent(0, 0); p != 100 {
+		t.Errorf("Expected empty upload to be 100%%, got %v instead", p)
+	}
+}
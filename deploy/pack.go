@@ -0,0 +1,196 @@
+package deploy
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/verbose"
+)
+
+// IgnoreFile is the file listing the patterns of files that are not deployed
+const IgnoreFile = ".weignore"
+
+// DefaultIgnorePatterns are never deployed
+var DefaultIgnorePatterns = []string{
+	".git",
+	".hg",
+	".svn",
+	".DS_Store",
+	containers.EnvFile,
+	IgnoreFile,
+}
+
+// ReadIgnoreFile reads the ignore patterns of a container directory
+// a missing ignore file means only the default patterns are used
+func ReadIgnoreFile(dir string) ([]string, error) {
+	var patterns = append([]string{}, DefaultIgnorePatterns...)
+	var file, err = os.Open(filepath.Join(dir, IgnoreFile))
+
+	switch {
+	case os.IsNotExist(err):
+		return patterns, nil
+	case err != nil:
+		return nil, errwrap.Wrapf("Can't read "+IgnoreFile+": {{err}}", err)
+	}
+
+	defer func() {
+		if ec := file.Close(); ec != nil {
+			verbose.Debug("Error closing " + IgnoreFile + ": " + ec.Error())
+		}
+	}()
+
+	var scanner = bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		var line = strings.TrimSpace(scanner.Text())
+
+		if line != "" && !strings.HasPrefix(line, "#") {
+			patterns = append(patterns, line)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, errwrap.Wrapf("Can't read "+IgnoreFile+": {{err}}", err)
+	}
+
+	return patterns, nil
+}
+
+// IsIgnored tells if a path (relative to the container directory) is ignored
+// patterns starting with / only match from the container directory
+// and patterns ending with / only match directories
+func IsIgnored(rel string, isDir bool, patterns []string) bool {
+	rel = filepath.ToSlash(rel)
+	var parts = strings.Split(rel, "/")
+
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "/") {
+			if !isDir {
+				continue
+			}
+
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+
+		if strings.HasPrefix(pattern, "/") {
+			if matched, _ := filepath.Match(strings.TrimPrefix(pattern, "/"), rel); matched {
+				return true
+			}
+
+			continue
+		}
+
+		if matched, _ := filepath.Match(pattern, rel); matched {
+			return true
+		}
+
+		if matched, _ := filepath.Match(pattern, parts[len(parts)-1]); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Pack writes a compressed archive of a container directory
+// and returns the number of files added to it
+func Pack(dir string, w io.Writer) (files int, err error) {
+	var patterns []string
+
+	if patterns, err = ReadIgnoreFile(dir); err != nil {
+		return 0, err
+	}
+
+	var gw = gzip.NewWriter(w)
+	var tw = tar.NewWriter(gw)
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		var rel, errRel = filepath.Rel(dir, path)
+
+		if errRel != nil || rel == "." {
+			return errRel
+		}
+
+		if IsIgnored(rel, info.IsDir(), patterns) {
+			verbose.Debug("Ignoring " + path)
+
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			verbose.Debug("Ignoring non-regular file " + path)
+			return nil
+		}
+
+		if err := addFile(tw, path, filepath.ToSlash(rel), info); err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			files++
+		}
+
+		return nil
+	})
+
+	if err == nil {
+		err = tw.Close()
+	}
+
+	if err == nil {
+		err = gw.Close()
+	}
+
+	if err != nil {
+		return files, errwrap.Wrapf("Can't package "+dir+": {{err}}", err)
+	}
+
+	return files, nil
+}
+
+func addFile(tw *tar.Writer, path, name string, info os.FileInfo) error {
+	var header, err = tar.FileInfoHeader(info, "")
+
+	if err != nil {
+		return err
+	}
+
+	header.Name = name
+
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if err = tw.WriteHeader(header); err != nil || info.IsDir() {
+		return err
+	}
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, file)
+
+	if ec := file.Close(); err == nil {
+		err = ec
+	}
+
+	return err
+}
@@ -0,0 +1,116 @@
+package deploy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestReadIgnoreFile(t *testing.T) {
+	var patterns, err = ReadIgnoreFile("mocks/myproject/web")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = append(append([]string{}, DefaultIgnorePatterns...), "build/", "*.log", "/node_modules")
+
+	if !reflect.DeepEqual(patterns, want) {
+		t.Errorf("Wanted patterns %v, got %v instead", want, patterns)
+	}
+}
+
+func TestReadIgnoreFileNotFound(t *testing.T) {
+	var patterns, err = ReadIgnoreFile("mocks/myproject")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if !reflect.DeepEqual(patterns, DefaultIgnorePatterns) {
+		t.Errorf("Wanted default patterns, got %v instead", patterns)
+	}
+}
+
+var isIgnoredCases = []struct {
+	rel   string
+	isDir bool
+	want  bool
+}{
+	{"index.html", false, false},
+	{"build", true, true},
+	{"build", false, false},
+	{"src/build", true, true},
+	{"logs/server.log", false, true},
+	{"node_modules", true, true},
+	{"src/node_modules", true, false},
+	{".git", true, true},
+	{"src/style.css", false, false},
+}
+
+func TestIsIgnored(t *testing.T) {
+	var patterns = []string{".git", "build/", "*.log", "/node_modules"}
+
+	for _, c := range isIgnoredCases {
+		if got := IsIgnored(c.rel, c.isDir, patterns); got != c.want {
+			t.Errorf("Wanted IsIgnored(%v, %v) to be %v, got %v instead", c.rel, c.isDir, c.want, got)
+		}
+	}
+}
+
+func TestPack(t *testing.T) {
+	var b bytes.Buffer
+	var files, err = Pack("mocks/myproject/web", &b)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if files != 3 {
+		t.Errorf("Expected 3 files to be packaged, got %v instead", files)
+	}
+
+	var want = []string{
+		"container.json",
+		"index.html",
+		"logs/",
+		"src/",
+		"src/style.css",
+	}
+
+	if got := listArchive(&b); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted archive to have %v, got %v instead", want, got)
+	}
+}
+
+func listArchive(r io.Reader) []string {
+	var gr, err = gzip.NewReader(r)
+
+	if err != nil {
+		panic(err)
+	}
+
+	var tr = tar.NewReader(gr)
+	var names = []string{}
+
+	for {
+		var header, err = tr.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			panic(err)
+		}
+
+		names = append(names, header.Name)
+	}
+
+	sort.Strings(names)
+	return names
+}
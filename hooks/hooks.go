@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/wedeploy/cli/verbose"
@@ -18,6 +19,8 @@ type Hooks struct {
 	BeforeDeploy string `json:"before_deploy"`
 	Deploy       string `json:"deploy"`
 	AfterDeploy  string `json:"after_deploy"`
+	BeforeLink   string `json:"before_link"`
+	AfterLink    string `json:"after_link"`
 }
 
 // HookError struct
@@ -30,8 +33,28 @@ func (he HookError) Error() string {
 	return fmt.Sprintf("Command %v failure: %v", he.Command, he.Err.Error())
 }
 
-// Build is 'build' hook
-const Build = "build"
+// Phase of the hooks lifecycle
+// its steps run in order: before, main, the phase action, after
+type Phase struct {
+	Name   string
+	Before string
+	Main   string
+	After  string
+
+	// warnNoMain warns when the phase has no main action hook
+	warnNoMain bool
+}
+
+const (
+	// Build is 'build' hook
+	Build = "build"
+
+	// Deploy is 'deploy' hook
+	Deploy = "deploy"
+
+	// Link is 'link' hook
+	Link = "link"
+)
 
 var (
 	// ErrMissingHook is used when the hook is missing
@@ -39,48 +62,88 @@ var (
 
 	outStream io.Writer = os.Stdout
 	errStream io.Writer = os.Stderr
+
+	// wdirMutex is held while hooks run, as they change the working directory
+	// of the whole process
+	wdirMutex sync.Mutex
 )
 
+// GetPhase gets the lifecycle phase for the given hook type
+func (h *Hooks) GetPhase(hookType string) (Phase, error) {
+	var p = Phase{
+		Name: hookType,
+	}
+
+	switch hookType {
+	case Build:
+		p.warnNoMain = true
+	case Deploy, Link:
+	default:
+		return p, ErrMissingHook
+	}
+
+	if h == nil {
+		return p, nil
+	}
+
+	switch hookType {
+	case Build:
+		p.Before, p.Main, p.After = h.BeforeBuild, h.Build, h.AfterBuild
+	case Deploy:
+		p.Before, p.Main, p.After = h.BeforeDeploy, h.Deploy, h.AfterDeploy
+	case Link:
+		p.Before, p.After = h.BeforeLink, h.AfterLink
+	}
+
+	return p, nil
+}
+
 // Run invokes the hooks for the given hook type on working directory
 func (h *Hooks) Run(hookType string, wdir string) error {
-	var owd, err = os.Getwd()
+	return h.RunPhase(hookType, wdir, nil)
+}
+
+// RunPhase invokes the hooks for the given hook type on working directory
+// and the phase action (if any) after the main hook.
+// The action doesn't run on the working directory.
+func (h *Hooks) RunPhase(hookType string, wdir string, action func() error) error {
+	var p, err = h.GetPhase(hookType)
 
 	if err != nil {
-		return errwrap.Wrapf("Can't get current working dir on hooks run: {{err}}", err)
+		return err
 	}
 
-	if wdir != "" {
-		if err = os.Chdir(wdir); err != nil {
-			return err
-		}
+	if p.warnNoMain && p.Main == "" && (p.Before != "" || p.After != "") {
+		fmt.Fprintf(errStream, "Error: no %v hook main action\n", p.Name)
 	}
 
-	switch hookType {
-	case "build":
-		err = h.runBuild()
-	default:
-		err = ErrMissingHook
+	if err = runSteps(wdir, p.Before, p.Main); err != nil {
+		return err
 	}
 
-	if wdir != "" {
-		if ech := os.Chdir(owd); ech != nil {
-			fmt.Fprintf(os.Stderr, "Multiple errors: %v\n", err)
-			panic(ech)
+	if action != nil {
+		if err = action(); err != nil {
+			return err
 		}
 	}
 
-	return err
+	return runSteps(wdir, p.After)
 }
 
-func (h *Hooks) runBuild() error {
-	if h.Build == "" && (h.BeforeBuild != "" || h.AfterBuild != "") {
-		fmt.Fprintf(errStream, "Error: no build hook main action\n")
+func runSteps(wdir string, steps ...string) error {
+	wdirMutex.Lock()
+	defer wdirMutex.Unlock()
+
+	var owd, err = os.Getwd()
+
+	if err != nil {
+		return errwrap.Wrapf("Can't get current working dir on hooks run: {{err}}", err)
 	}
 
-	var steps = []string{
-		h.BeforeBuild,
-		h.Build,
-		h.AfterBuild,
+	if wdir != "" {
+		if err = os.Chdir(wdir); err != nil {
+			return err
+		}
 	}
 
 	for _, eachStep := range steps {
@@ -88,17 +151,24 @@ func (h *Hooks) runBuild() error {
 			continue
 		}
 
-		var err = Run(eachStep)
-
-		if err != nil {
-			return HookError{
+		if err = Run(eachStep); err != nil {
+			err = HookError{
 				Command: eachStep,
 				Err:     err,
 			}
+
+			break
 		}
 	}
 
-	return nil
+	if wdir != "" {
+		if ech := os.Chdir(owd); ech != nil {
+			fmt.Fprintf(os.Stderr, "Multiple errors: %v\n", err)
+			panic(ech)
+		}
+	}
+
+	return err
 }
 
 // Run a process synchronously inheriting stderr and stdout
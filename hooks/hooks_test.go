@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
@@ -45,6 +47,34 @@ var HooksCases = []HooksProvider{
 		WantErr:    "Error: no build hook main action\n",
 		WantError:  nil,
 	},
+	HooksProvider{
+		Type: Deploy,
+		Hook: &Hooks{
+			BeforeDeploy: "echo before deploy",
+			Deploy:       "echo during deploy",
+			AfterDeploy:  "echo after deploy",
+		},
+		WantOutput: "before deploy\nduring deploy\nafter deploy\n",
+	},
+	HooksProvider{
+		Type: Deploy,
+		Hook: &Hooks{
+			AfterDeploy: "echo after deploy",
+		},
+		WantOutput: "after deploy\n",
+	},
+	HooksProvider{
+		Type: Link,
+		Hook: &Hooks{
+			BeforeLink: "echo before link",
+			AfterLink:  "echo after link",
+			Build:      "echo build",
+		},
+		WantOutput: "before link\nafter link\n",
+	},
+	HooksProvider{
+		Type: Build,
+	},
 	HooksProvider{
 		Type:      "not implemented",
 		WantError: ErrMissingHook,
@@ -134,6 +164,127 @@ func TestRunHooks(t *testing.T) {
 	}
 }
 
+func TestRunPhase(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Not testing hooks.RunPhase() on Windows")
+	}
+
+	bufErrStream.Reset()
+	bufOutStream.Reset()
+
+	var h = &Hooks{
+		BeforeDeploy: "echo before",
+		Deploy:       "echo main",
+		AfterDeploy:  "echo after",
+	}
+
+	var err = h.RunPhase(Deploy, "", func() error {
+		fmt.Fprintf(outStream, "action\n")
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = "before\nmain\naction\nafter\n"
+
+	if bufOutStream.String() != want {
+		t.Errorf("Wanted output %v, got %v instead", want, bufOutStream.String())
+	}
+}
+
+func TestRunPhaseActionFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Not testing hooks.RunPhase() on Windows")
+	}
+
+	bufErrStream.Reset()
+	bufOutStream.Reset()
+
+	var h = &Hooks{
+		BeforeLink: "echo before",
+		AfterLink:  "echo after",
+	}
+
+	var wantErr = errors.New("link failure")
+
+	var err = h.RunPhase(Link, "", func() error {
+		return wantErr
+	})
+
+	if err != wantErr {
+		t.Errorf("Expected error %v, got %v instead", wantErr, err)
+	}
+
+	if bufOutStream.String() != "before\n" {
+		t.Errorf("Expected after hook to not run, got output %v instead", bufOutStream.String())
+	}
+}
+
+func TestRunPhaseNilHooks(t *testing.T) {
+	var h *Hooks
+	var called bool
+
+	var err = h.RunPhase(Deploy, "", func() error {
+		called = true
+		return nil
+	})
+
+	if err != nil || !called {
+		t.Errorf("Expected action to run without hooks, got %v instead", err)
+	}
+}
+
+func TestRunPhaseWorkingDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Not testing hooks.RunPhase() on Windows")
+	}
+
+	bufErrStream.Reset()
+	bufOutStream.Reset()
+
+	var owd, err = os.Getwd()
+
+	if err != nil {
+		panic(err)
+	}
+
+	dir, err := ioutil.TempDir(os.TempDir(), "we-hooks")
+
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() {
+		if err := os.Remove(dir); err != nil {
+			panic(err)
+		}
+	}()
+
+	var h = &Hooks{
+		BeforeBuild: "basename $(pwd)",
+	}
+
+	err = h.RunPhase(Build, dir, func() error {
+		var wd, err = os.Getwd()
+
+		if wd != owd {
+			t.Errorf("Expected action to run on the original working directory, got %v instead", wd)
+		}
+
+		return err
+	})
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if bufOutStream.String() != filepath.Base(dir)+"\n" {
+		t.Errorf("Expected hook to run on %v, got %v instead", dir, bufOutStream.String())
+	}
+}
+
 func TestRun(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("Not testing hooks.Run() on Windows")
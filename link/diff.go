@@ -0,0 +1,225 @@
+package link
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/wedeploy/cli/apihelper"
+	"github.com/wedeploy/cli/color"
+	"github.com/wedeploy/cli/containers"
+)
+
+// Actions linking a container takes
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+)
+
+// Change of a container definition field
+// Old or New is empty when the field is added or removed
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Plan of what linking a container does
+type Plan struct {
+	ContainerPath string
+	Container     *containers.Container
+	Action        string
+	Changes       []Change
+}
+
+// maskedValue replaces the values of environment variables looking like secrets
+var maskedValue = "********"
+
+// ignoredDiffFields are not part of the container definition
+var ignoredDiffFields = map[string]bool{
+	"id":     true,
+	"health": true,
+}
+
+// Plan what linking the containers does comparing the local definitions
+// with the currently linked ones
+func (m *Machine) Plan() ([]Plan, error) {
+	var plans = []Plan{}
+
+	for _, l := range m.Links {
+		var p, err = getPlan(m.Project.ID, l)
+
+		if err != nil {
+			return nil, err
+		}
+
+		plans = append(plans, p)
+	}
+
+	return plans, nil
+}
+
+func getPlan(projectID string, l *Link) (Plan, error) {
+	var p = Plan{
+		ContainerPath: l.ContainerPath,
+		Container:     l.Container,
+	}
+
+	var linked, err = containers.Get(projectID, l.Container.ID)
+
+	if ae, ok := err.(*apihelper.APIFault); ok && ae.Code == 404 {
+		p.Action = ActionCreate
+		p.Changes = Diff(nil, l.Container)
+		return p, nil
+	}
+
+	if err != nil {
+		return p, err
+	}
+
+	p.Changes = Diff(&linked, l.Container)
+	p.Action = ActionUpdate
+
+	if len(p.Changes) == 0 {
+		p.Action = ActionUnchanged
+	}
+
+	return p, nil
+}
+
+// Diff gets the field-level changes between the linked and local container definitions
+// the values of environment variables looking like secrets are masked
+func Diff(linked, local *containers.Container) []Change {
+	var of = flattenContainer(linked)
+	var nf = flattenContainer(local)
+	var fields = []string{}
+
+	for k := range of {
+		fields = append(fields, k)
+	}
+
+	for k := range nf {
+		if _, ok := of[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+
+	sort.Strings(fields)
+
+	var changes = []Change{}
+
+	for _, k := range fields {
+		if of[k] != nf[k] {
+			changes = append(changes, maskChange(Change{
+				Field: k,
+				Old:   of[k],
+				New:   nf[k],
+			}))
+		}
+	}
+
+	return changes
+}
+
+func maskChange(c Change) Change {
+	if !strings.HasPrefix(c.Field, "env.") ||
+		!containers.LooksSecret(strings.TrimPrefix(c.Field, "env.")) {
+		return c
+	}
+
+	if c.Old != "" {
+		c.Old = maskedValue
+	}
+
+	if c.New != "" {
+		c.New = maskedValue
+	}
+
+	return c
+}
+
+// flattenContainer maps the JSON fields of a container (i.e., env.KEY)
+// to their JSON encoded values, ignoring empty values
+func flattenContainer(c *containers.Container) map[string]string {
+	var fields = map[string]string{}
+
+	if c == nil {
+		return fields
+	}
+
+	var m map[string]interface{}
+	var b, err = json.Marshal(c)
+
+	if err == nil {
+		err = json.Unmarshal(b, &m)
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	flatten("", m, fields)
+	return fields
+}
+
+func flatten(prefix string, m map[string]interface{}, fields map[string]string) {
+	for k, v := range m {
+		if prefix == "" && ignoredDiffFields[k] {
+			continue
+		}
+
+		var key = prefix + k
+
+		switch value := v.(type) {
+		case nil:
+		case string:
+			if value != "" {
+				fields[key] = fmt.Sprintf("%q", value)
+			}
+		case map[string]interface{}:
+			flatten(key+".", value, fields)
+		default:
+			var b, err = json.Marshal(value)
+
+			if err != nil {
+				panic(err)
+			}
+
+			fields[key] = string(b)
+		}
+	}
+}
+
+// PrintPlans prints what linking the containers does
+func PrintPlans(w io.Writer, plans []Plan) {
+	for _, p := range plans {
+		switch p.Action {
+		case ActionCreate:
+			fmt.Fprintf(w, "%v %v would be created\n",
+				color.Format(color.FgGreen, "+"), p.Container.ID)
+		case ActionUpdate:
+			fmt.Fprintf(w, "%v %v would be updated\n",
+				color.Format(color.FgYellow, "~"), p.Container.ID)
+		default:
+			fmt.Fprintf(w, "= %v would be left untouched\n", p.Container.ID)
+		}
+
+		for _, c := range p.Changes {
+			printChange(w, c)
+		}
+	}
+}
+
+func printChange(w io.Writer, c Change) {
+	switch {
+	case c.Old == "":
+		fmt.Fprintf(w, "    %v %v: %v\n", color.Format(color.FgGreen, "+"), c.Field, c.New)
+	case c.New == "":
+		fmt.Fprintf(w, "    %v %v: %v\n", color.Format(color.FgRed, "-"), c.Field, c.Old)
+	default:
+		fmt.Fprintf(w, "    %v %v: %v -> %v\n", color.Format(color.FgYellow, "~"), c.Field, c.Old, c.New)
+	}
+}
@@ -0,0 +1,172 @@
+package link
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/wedeploy/cli/color"
+	"github.com/wedeploy/cli/configmock"
+	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/hooks"
+	"github.com/wedeploy/cli/servertest"
+)
+
+func TestDiff(t *testing.T) {
+	var linked = &containers.Container{
+		ID:        "api",
+		Health:    "up",
+		Type:      "nodejs",
+		Instances: 1,
+		Env: map[string]string{
+			"LOG_LEVEL": "info",
+			"REGION":    "us",
+		},
+		Hooks: &hooks.Hooks{
+			Build: "npm install",
+		},
+	}
+
+	var local = &containers.Container{
+		ID:        "api",
+		Type:      "nodejs",
+		Instances: 2,
+		Env: map[string]string{
+			"LOG_LEVEL": "debug",
+			"DEBUG":     "true",
+		},
+		Hooks: &hooks.Hooks{
+			Build:      "npm install",
+			AfterBuild: "npm test",
+		},
+	}
+
+	var want = []Change{
+		Change{Field: "env.DEBUG", New: `"true"`},
+		Change{Field: "env.LOG_LEVEL", Old: `"info"`, New: `"debug"`},
+		Change{Field: "env.REGION", Old: `"us"`},
+		Change{Field: "hooks.after_build", New: `"npm test"`},
+		Change{Field: "instances", Old: "1", New: "2"},
+	}
+
+	if got := Diff(linked, local); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted changes %v, got %v instead", want, got)
+	}
+}
+
+func TestDiffMasksSecrets(t *testing.T) {
+	var linked = &containers.Container{
+		ID: "api",
+		Env: map[string]string{
+			"DB_PASSWORD": "old-secret",
+			"API_TOKEN":   "token",
+		},
+	}
+
+	var local = &containers.Container{
+		ID: "api",
+		Env: map[string]string{
+			"DB_PASSWORD": "new-secret",
+			"SECRET_KEY":  "key",
+		},
+	}
+
+	var want = []Change{
+		Change{Field: "env.API_TOKEN", Old: maskedValue},
+		Change{Field: "env.DB_PASSWORD", Old: maskedValue, New: maskedValue},
+		Change{Field: "env.SECRET_KEY", New: maskedValue},
+	}
+
+	if got := Diff(linked, local); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted changes %v, got %v instead", want, got)
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	var linked = &containers.Container{ID: "db", Health: "up", Type: "wedeploy/data"}
+	var local = &containers.Container{ID: "db", Type: "wedeploy/data", Hooks: &hooks.Hooks{}}
+
+	if got := Diff(linked, local); len(got) != 0 {
+		t.Errorf("Expected no changes, got %v instead", got)
+	}
+}
+
+func TestDiffCreate(t *testing.T) {
+	var local = &containers.Container{
+		ID:        "web",
+		Type:      "wedeploy/hosting",
+		DependsOn: []string{"api"},
+	}
+
+	var want = []Change{
+		Change{Field: "dependsOn", New: `["api"]`},
+		Change{Field: "type", New: `"wedeploy/hosting"`},
+	}
+
+	if got := Diff(nil, local); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted changes %v, got %v instead", want, got)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/projects/deps/containers/db",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			fmt.Fprintf(w, `{"id": "db", "health": "up", "type": "wedeploy/data", "tags": ["backend", "data"]}`)
+		})
+
+	servertest.Mux.HandleFunc("/projects/deps/containers/api",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			fmt.Fprintf(w, `{"id": "api", "health": "up", "instances": 3}`)
+		})
+
+	servertest.Mux.HandleFunc("/projects/deps/containers/web",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			w.WriteHeader(404)
+			fmt.Fprintf(w, `{"code": 404, "message": "Not Found"}`)
+		})
+
+	var m = &Machine{
+		DryRun: true,
+	}
+
+	if err := m.Setup("mocks/project-with-deps", []string{"db", "api", "web"}); err != nil {
+		panic(err)
+	}
+
+	var plans, err = m.Plan()
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var b bytes.Buffer
+	var defaultNoColor = color.NoColor
+	color.NoColor = true
+	PrintPlans(&b, plans)
+	color.NoColor = defaultNoColor
+
+	var want = `= db would be left untouched
+~ api would be updated
+    + dependsOn: ["db"]
+    - instances: 3
+    + tags: ["backend"]
++ web would be created
+    + dependsOn: ["api","cdn"]
+    + tags: ["frontend"]
+`
+
+	if b.String() != want {
+		t.Errorf("Wanted plan %v, got %v instead", want, b.String())
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
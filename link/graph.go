@@ -0,0 +1,141 @@
+package link
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DependencyCycleError is used when containers depend on each other
+type DependencyCycleError struct {
+	Cycle []string
+}
+
+func (de DependencyCycleError) Error() string {
+	return fmt.Sprintf("Dependency cycle detected: %v", strings.Join(de.Cycle, " -> "))
+}
+
+// getWaves groups the links in waves where each link only depends on
+// links of previous waves (dependencies not being linked are ignored)
+func getWaves(links []*Link) ([][]*Link, error) {
+	var byID = map[string]*Link{}
+
+	for _, l := range links {
+		byID[l.Container.ID] = l
+	}
+
+	if cycle := findCycle(byID); cycle != nil {
+		return nil, DependencyCycleError{
+			Cycle: cycle,
+		}
+	}
+
+	var waves = [][]*Link{}
+	var done = map[string]bool{}
+
+	for len(done) != len(byID) {
+		var wave = []*Link{}
+
+		for _, l := range links {
+			if !done[l.Container.ID] && dependenciesDone(l, byID, done) {
+				wave = append(wave, l)
+			}
+		}
+
+		for _, l := range wave {
+			done[l.Container.ID] = true
+		}
+
+		waves = append(waves, wave)
+	}
+
+	return waves, nil
+}
+
+func dependenciesDone(l *Link, byID map[string]*Link, done map[string]bool) bool {
+	for _, dep := range l.Container.DependsOn {
+		if _, ok := byID[dep]; ok && !done[dep] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// findCycle finds a dependency cycle using a depth-first search
+func findCycle(byID map[string]*Link) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	var state = map[string]int{}
+	var path = []string{}
+	var cycle []string
+
+	var visit func(id string) bool
+
+	visit = func(id string) bool {
+		state[id] = visiting
+		path = append(path, id)
+
+		for _, dep := range byID[id].Container.DependsOn {
+			if _, ok := byID[dep]; !ok {
+				continue
+			}
+
+			switch state[dep] {
+			case visiting:
+				cycle = append(getCyclePath(path, dep), dep)
+				return true
+			case 0:
+				if visit(dep) {
+					return true
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[id] = visited
+		return false
+	}
+
+	var ids = make([]string, 0, len(byID))
+
+	for id := range byID {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if state[id] == 0 && visit(id) {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+func getCyclePath(path []string, start string) []string {
+	for i, id := range path {
+		if id == start {
+			return append([]string{}, path[i:]...)
+		}
+	}
+
+	return path
+}
+
+// getDependencies gets the IDs of the containers other links depend on
+func getDependencies(links []*Link) map[string]bool {
+	var deps = map[string]bool{}
+
+	for _, l := range links {
+		for _, dep := range l.Container.DependsOn {
+			deps[dep] = true
+		}
+	}
+
+	return deps
+}
@@ -0,0 +1,98 @@
+package link
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wedeploy/cli/containers"
+)
+
+func newTestLink(id string, dependsOn ...string) *Link {
+	return &Link{
+		Container: &containers.Container{
+			ID:        id,
+			DependsOn: dependsOn,
+		},
+	}
+}
+
+func getWavesIDs(waves [][]*Link) [][]string {
+	var ids = [][]string{}
+
+	for _, wave := range waves {
+		var w = []string{}
+
+		for _, l := range wave {
+			w = append(w, l.Container.ID)
+		}
+
+		ids = append(ids, w)
+	}
+
+	return ids
+}
+
+func TestGetWaves(t *testing.T) {
+	var links = []*Link{
+		newTestLink("web", "api", "cdn"),
+		newTestLink("api", "db", "cache"),
+		newTestLink("db"),
+		newTestLink("cache"),
+		newTestLink("worker", "db"),
+	}
+
+	var waves, err = getWaves(links)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = [][]string{
+		[]string{"db", "cache"},
+		[]string{"api", "worker"},
+		[]string{"web"},
+	}
+
+	if got := getWavesIDs(waves); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted waves %v, got %v instead", want, got)
+	}
+}
+
+func TestGetWavesNoDependencies(t *testing.T) {
+	var waves, err = getWaves([]*Link{newTestLink("a"), newTestLink("b")})
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = [][]string{[]string{"a", "b"}}
+
+	if got := getWavesIDs(waves); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted waves %v, got %v instead", want, got)
+	}
+}
+
+func TestGetWavesCycle(t *testing.T) {
+	var links = []*Link{
+		newTestLink("a", "b"),
+		newTestLink("b", "c"),
+		newTestLink("c", "a"),
+		newTestLink("d"),
+	}
+
+	var _, err = getWaves(links)
+
+	var want = "Dependency cycle detected: a -> b -> c -> a"
+
+	if err == nil || err.Error() != want {
+		t.Errorf("Wanted error %v, got %v instead", want, err)
+	}
+}
+
+func TestGetWavesSelfDependency(t *testing.T) {
+	var _, err = getWaves([]*Link{newTestLink("a", "a")})
+
+	if _, ok := err.(DependencyCycleError); !ok {
+		t.Errorf("Expected dependency cycle error, got %v instead", err)
+	}
+}
@@ -5,15 +5,18 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/wedeploy/cli/config"
 	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/definition"
+	"github.com/wedeploy/cli/hooks"
 	"github.com/wedeploy/cli/list"
 	"github.com/wedeploy/cli/projects"
 	"github.com/wedeploy/cli/verbose"
+	"github.com/wedeploy/cli/workerpool"
 )
 
 // Machine structure
@@ -24,11 +27,23 @@ type Machine struct {
 	Errors      *Errors
 	FErrStream  io.Writer
 	ErrorsMutex sync.Mutex
-	dirMutex    sync.Mutex
-	queue       sync.WaitGroup
 	Watcher     *list.Watcher
-	list        *list.List
-	end         bool
+	// Parallel is how many containers are linked at the same time (zero for no limit)
+	Parallel int
+	// Timeout is how long to wait for each container to be linked and up
+	// before reporting it as failed (zero for no timeout)
+	Timeout time.Duration
+	// DependencyTimeout is how long to wait for dependencies to be up
+	DependencyTimeout time.Duration
+	// KeepWatching keeps the status display live after the containers are up
+	KeepWatching bool
+	// DryRun sets up the machine without creating the project
+	DryRun  bool
+	list    *list.List
+	results []workerpool.Result
+	end     bool
+	// watcherMutex guards the Watcher, used by relink from the file watcher
+	watcherMutex sync.Mutex
 }
 
 // Link holds the information of container to be linked
@@ -51,6 +66,11 @@ type Errors struct {
 
 var outStream io.Writer = os.Stdout
 
+// DefaultDependencyTimeout is how long to wait for the dependencies of a container to be up
+var DefaultDependencyTimeout = 5 * time.Minute
+
+var healthCheckInterval = time.Second
+
 // New Container link
 func New(project *projects.Project, dir string) (*Link, error) {
 	var l = &Link{
@@ -97,7 +117,9 @@ func (m *Machine) Setup(projectPath string, list []string) error {
 	m.Project = project
 	m.ProjectPath = projectPath
 
-	err = m.createProject()
+	if !m.DryRun {
+		err = m.createProject()
+	}
 
 	if err != nil {
 		return err
@@ -107,6 +129,7 @@ func (m *Machine) Setup(projectPath string, list []string) error {
 		m.mount(dir)
 	}
 
+	_, err = getWaves(m.Links)
 	return err
 }
 
@@ -123,9 +146,33 @@ func (m *Machine) Watch() {
 		Containers: cs,
 	})
 
-	m.Watcher = list.NewWatcher(m.list)
-	m.Watcher.StopCondition = m.linkedContainersUp
-	m.Watcher.Start()
+	var w = list.NewWatcher(m.list)
+
+	if !m.KeepWatching {
+		w.StopCondition = m.linkedContainersUp
+	}
+
+	m.watcherMutex.Lock()
+	m.Watcher = w
+	m.watcherMutex.Unlock()
+
+	w.Start()
+}
+
+// printf prints through the watcher while it is active, so the list isn't garbled
+func (m *Machine) printf(w io.Writer, format string, a ...interface{}) {
+	m.watcherMutex.Lock()
+	var watcher = m.Watcher
+	m.watcherMutex.Unlock()
+
+	if watcher != nil {
+		watcher.Printf(format, a...)
+		return
+	}
+
+	if w != nil {
+		fmt.Fprintf(w, format, a...)
+	}
 }
 
 func (m *Machine) linkedContainersUp() bool {
@@ -135,7 +182,7 @@ func (m *Machine) linkedContainersUp() bool {
 
 	var projectWatched = m.list.Projects[0]
 
-	for _, link := range m.Links {
+	for _, link := range m.Linked() {
 		c, ok := projectWatched.Containers[link.Container.ID]
 
 		if !ok || c.Health != "up" {
@@ -168,63 +215,238 @@ func (m *Machine) condAuthProject() error {
 		return nil
 	}
 
-	var authFile = filepath.Join(m.ProjectPath, "auth.json")
-	var err = projects.SetAuth(m.Project.ID, authFile)
+	var authFile, err = definition.Find(m.ProjectPath, "auth")
 
 	if os.IsNotExist(err) {
-		verbose.Debug("Jumped uploading auth.json for project: does not exist.")
+		verbose.Debug("Jumped uploading auth for project: does not exist.")
 		return nil
 	}
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return projects.SetAuth(m.Project.ID, authFile)
 }
 
 // Run links the containers of the list input
+// containers are linked in waves, after the containers they depend on are up
 func (m *Machine) Run() {
-	m.queue.Add(len(m.Links))
-	m.linkAll()
-	m.queue.Wait()
+	var waves, err = getWaves(m.Links)
+
+	if err != nil {
+		for _, cl := range m.Links {
+			m.logError(cl.ContainerPath, err)
+		}
+
+		m.end = true
+		return
+	}
+
+	var deps = getDependencies(m.Links)
+	var failed = map[string]bool{}
+
+	for i, wave := range waves {
+		m.linkAll(m.filterFailedDependencies(wave, failed))
+
+		if i != len(waves)-1 {
+			m.waitDependencies(wave, deps, failed)
+		}
+	}
+
 	m.end = true
 }
 
-func (m *Machine) linkAll() {
-	for _, cl := range m.Links {
-		go m.doLink(cl)
+// linkAll links the containers on a worker pool
+func (m *Machine) linkAll(links []*Link) {
+	var tasks = []workerpool.Task{}
+
+	for _, cl := range links {
+		tasks = append(tasks, workerpool.Task{
+			Name: cl.Container.ID,
+			Run:  m.linkTask(cl),
+		})
+	}
+
+	var results = workerpool.Run(tasks, m.Parallel, m.Timeout)
+
+	for n, r := range results {
+		if r.Err != nil {
+			m.logError(links[n].ContainerPath, r.Err)
+		}
 	}
+
+	m.results = append(m.results, results...)
 }
 
-func (m *Machine) doLink(cl *Link) {
-	var err = m.link(cl)
+// linkTask links a container and, when there is a timeout,
+// waits for the container to be up
+func (m *Machine) linkTask(cl *Link) func(cancel <-chan struct{}) error {
+	return func(cancel <-chan struct{}) error {
+		if err := m.link(cl); err != nil || m.Timeout == 0 {
+			return err
+		}
 
-	if err != nil {
-		m.logError(cl.ContainerPath, err)
+		if !m.waitUp(cl.Container.ID, m.Timeout, cancel) {
+			return workerpool.TimeoutError{
+				Timeout: m.Timeout,
+			}
+		}
+
+		return nil
+	}
+}
+
+// Results of linking each container, in the order they were linked
+func (m *Machine) Results() []workerpool.Result {
+	return m.results
+}
+
+// filterFailedDependencies removes the links depending on failed containers
+func (m *Machine) filterFailedDependencies(wave []*Link, failed map[string]bool) []*Link {
+	var links = []*Link{}
+
+	for _, cl := range wave {
+		var dependency = getFailedDependency(cl, failed)
+
+		if dependency != "" {
+			failed[cl.Container.ID] = true
+			m.logError(cl.ContainerPath,
+				fmt.Errorf("Dependency %v is not up", dependency))
+			continue
+		}
+
+		links = append(links, cl)
 	}
 
-	m.queue.Done()
+	return links
+}
+
+func getFailedDependency(cl *Link, failed map[string]bool) string {
+	for _, dep := range cl.Container.DependsOn {
+		if failed[dep] {
+			return dep
+		}
+	}
+
+	return ""
+}
+
+// waitDependencies waits for the containers of a wave other containers
+// depend on to be up, marking the ones that fail or time out
+func (m *Machine) waitDependencies(wave []*Link, deps map[string]bool, failed map[string]bool) {
+	var linked = map[string]bool{}
+
+	for _, cl := range m.Linked() {
+		linked[cl.Container.ID] = true
+	}
+
+	for _, cl := range wave {
+		var id = cl.Container.ID
+
+		if !deps[id] {
+			continue
+		}
+
+		if !linked[id] || !m.waitUp(id, m.getDependencyTimeout(), nil) {
+			failed[id] = true
+		}
+	}
+}
+
+func (m *Machine) getDependencyTimeout() time.Duration {
+	if m.DependencyTimeout == 0 {
+		return DefaultDependencyTimeout
+	}
+
+	return m.DependencyTimeout
+}
+
+// waitUp waits for a container to be up until the timeout or cancel is closed
+func (m *Machine) waitUp(containerID string, timeout time.Duration, cancel <-chan struct{}) bool {
+	var deadline = time.Now().Add(timeout)
+
+	verbose.Debug("Waiting for container " + containerID + " to be up")
+
+	for {
+		var c, err = containers.Get(m.Project.ID, containerID)
+
+		switch {
+		case err != nil:
+			verbose.Debug("Can't get container " + containerID + " health: " + err.Error())
+		case c.Health == "up":
+			return true
+		}
+
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		select {
+		case <-cancel:
+			return false
+		case <-time.After(healthCheckInterval):
+		}
+	}
 }
 
 func (m *Machine) link(l *Link) error {
-	m.dirMutex.Lock()
-	var err = containers.Link(m.Project.ID,
-		l.ContainerPath,
-		l.Container)
-	m.dirMutex.Unlock()
-	runtime.Gosched()
+	return l.Container.Hooks.RunPhase(hooks.Link, l.ContainerPath, func() error {
+		return containers.Link(m.Project.ID,
+			l.ContainerPath,
+			l.Container)
+	})
+}
 
-	return err
+// Linked returns the containers linked without errors
+func (m *Machine) Linked() []*Link {
+	var failed = map[string]bool{}
+	var linked = []*Link{}
+
+	m.ErrorsMutex.Lock()
+
+	for _, e := range m.Errors.List {
+		failed[e.ContainerPath] = true
+	}
+
+	m.ErrorsMutex.Unlock()
+
+	for _, l := range m.Links {
+		if !failed[l.ContainerPath] {
+			linked = append(linked, l)
+		}
+	}
+
+	return linked
+}
+
+// Register the successfully linked containers on a registry
+func (m *Machine) Register(r *Registry) {
+	for _, l := range m.Linked() {
+		r.Add(RegistryEntry{
+			ProjectID:     m.Project.ID,
+			ContainerID:   l.Container.ID,
+			ProjectPath:   getAbsPath(m.ProjectPath),
+			ContainerPath: getAbsPath(l.ContainerPath),
+			Local:         config.Context.Local,
+		})
+	}
 }
 
 func (m *Machine) logError(dir string, err error) {
-	m.ErrorsMutex.Lock()
-	m.Errors.List = append(m.Errors.List, ContainerError{
-		ContainerPath: dir,
-		Error:         err,
-	})
+	m.addError(dir, err)
 
 	if m.FErrStream != nil {
 		fmt.Fprintf(m.FErrStream, "%v/ dir error: %v\n", dir, err)
 	}
+}
 
+func (m *Machine) addError(dir string, err error) {
+	m.ErrorsMutex.Lock()
+	m.Errors.List = append(m.Errors.List, ContainerError{
+		ContainerPath: dir,
+		Error:         err,
+	})
 	m.ErrorsMutex.Unlock()
 }
 
@@ -236,5 +458,21 @@ func (m *Machine) mount(dir string) {
 		return
 	}
 
+	if len(l.Container.Unresolved) != 0 && m.FErrStream != nil {
+		fmt.Fprintf(m.FErrStream, "Warning: unresolved references on container %v: %v\n",
+			l.Container.ID, strings.Join(l.Container.Unresolved, ", "))
+	}
+
 	m.Links = append(m.Links, l)
 }
+
+func getAbsPath(path string) string {
+	var abs, err = filepath.Abs(path)
+
+	if err != nil {
+		verbose.Debug("Can't get absolute path for "+path+":", err)
+		return path
+	}
+
+	return abs
+}
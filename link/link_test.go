@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/wedeploy/cli/apihelper"
 	"github.com/wedeploy/cli/configmock"
@@ -13,6 +17,7 @@ import (
 	"github.com/wedeploy/cli/projects"
 	"github.com/wedeploy/cli/servertest"
 	"github.com/wedeploy/cli/tdata"
+	"github.com/wedeploy/cli/workerpool"
 )
 
 func TestNew(t *testing.T) {
@@ -286,3 +291,246 @@ func TestAllInstallContainerError(t *testing.T) {
 	servertest.Teardown()
 	outStream = defaultOutStream
 }
+
+func TestRegister(t *testing.T) {
+	configmock.Setup()
+	defer configmock.Teardown()
+
+	var project, err = projects.Read("mocks/myproject")
+
+	if err != nil {
+		panic(err)
+	}
+
+	l, err := New(project, "mocks/myproject/mycontainer")
+
+	if err != nil {
+		panic(err)
+	}
+
+	var m = &Machine{
+		Project:     project,
+		ProjectPath: "mocks/myproject",
+		Links:       []*Link{l, &Link{ContainerPath: "failed"}},
+		Errors: &Errors{
+			List: []ContainerError{
+				ContainerError{ContainerPath: "failed", Error: os.ErrNotExist},
+			},
+		},
+	}
+
+	var r = &Registry{}
+	m.Register(r)
+
+	if len(r.Entries) != 1 {
+		t.Fatalf("Expected 1 registered container, got %v instead", r.Entries)
+	}
+
+	var entry = r.Entries[0]
+
+	if entry.ProjectID != "project" || entry.ContainerID != l.Container.ID {
+		t.Errorf("Unexpected registry entry %v", entry)
+	}
+
+	if !filepath.IsAbs(entry.ProjectPath) || !filepath.IsAbs(entry.ContainerPath) {
+		t.Errorf("Expected registry entry paths to be absolute, got %v instead", entry)
+	}
+
+	if entry.Local != "" {
+		t.Errorf("Expected entry to be on the default local infrastructure, got %v instead", entry.Local)
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	var defaultOutStream = outStream
+	outStream = &bytes.Buffer{}
+	defer func() {
+		outStream = defaultOutStream
+	}()
+
+	var defaultHealthCheckInterval = healthCheckInterval
+	healthCheckInterval = time.Millisecond
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/projects",
+		func(w http.ResponseWriter, r *http.Request) {})
+
+	var linked = []string{}
+	var m sync.Mutex
+
+	servertest.Mux.HandleFunc("/deploy",
+		func(w http.ResponseWriter, r *http.Request) {
+			m.Lock()
+			linked = append(linked, r.URL.Query().Get("containerId"))
+			m.Unlock()
+		})
+
+	servertest.Mux.HandleFunc("/projects/deps/containers/",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			fmt.Fprintf(w, `{"health": "up"}`)
+		})
+
+	var machine = &Machine{}
+
+	if err := machine.Setup("mocks/project-with-deps", []string{"web", "api", "db"}); err != nil {
+		t.Errorf("Unexpected error %v on linking", err)
+	}
+
+	machine.Run()
+
+	var want = []string{"db", "api", "web"}
+
+	if !reflect.DeepEqual(linked, want) {
+		t.Errorf("Wanted containers linked in order %v, got %v instead", want, linked)
+	}
+
+	if len(machine.Errors.List) != 0 {
+		t.Errorf("Expected no linking errors, got %v instead", machine.Errors)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+	healthCheckInterval = defaultHealthCheckInterval
+}
+
+func TestDependenciesNotUp(t *testing.T) {
+	var defaultOutStream = outStream
+	outStream = &bytes.Buffer{}
+	defer func() {
+		outStream = defaultOutStream
+	}()
+
+	var defaultHealthCheckInterval = healthCheckInterval
+	healthCheckInterval = time.Millisecond
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/projects",
+		func(w http.ResponseWriter, r *http.Request) {})
+
+	var linked = []string{}
+
+	servertest.Mux.HandleFunc("/deploy",
+		func(w http.ResponseWriter, r *http.Request) {
+			linked = append(linked, r.URL.Query().Get("containerId"))
+		})
+
+	servertest.Mux.HandleFunc("/projects/deps/containers/db",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			fmt.Fprintf(w, `{"health": "down"}`)
+		})
+
+	var machine = &Machine{
+		DependencyTimeout: 10 * time.Millisecond,
+	}
+
+	if err := machine.Setup("mocks/project-with-deps", []string{"web", "api", "db"}); err != nil {
+		t.Errorf("Unexpected error %v on linking", err)
+	}
+
+	machine.Run()
+
+	if !reflect.DeepEqual(linked, []string{"db"}) {
+		t.Errorf("Expected only db to be linked, got %v instead", linked)
+	}
+
+	var failed = map[string]string{}
+
+	for _, e := range machine.Errors.List {
+		failed[filepath.Base(e.ContainerPath)] = e.Error.Error()
+	}
+
+	var want = map[string]string{
+		"api": "Dependency db is not up",
+		"web": "Dependency api is not up",
+	}
+
+	if !reflect.DeepEqual(failed, want) {
+		t.Errorf("Wanted errors %v, got %v instead", want, failed)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+	healthCheckInterval = defaultHealthCheckInterval
+}
+
+func TestSetupDependencyCycle(t *testing.T) {
+	var defaultOutStream = outStream
+	outStream = &bytes.Buffer{}
+	defer func() {
+		outStream = defaultOutStream
+	}()
+
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/projects",
+		func(w http.ResponseWriter, r *http.Request) {})
+
+	var machine = &Machine{}
+	var err = machine.Setup("mocks/project-with-cycle", []string{"a", "b"})
+
+	if _, ok := err.(DependencyCycleError); !ok {
+		t.Errorf("Expected dependency cycle error, got %v instead", err)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestLinkTimeout(t *testing.T) {
+	var defaultOutStream = outStream
+	outStream = &bytes.Buffer{}
+	defer func() {
+		outStream = defaultOutStream
+	}()
+
+	var defaultHealthCheckInterval = healthCheckInterval
+	healthCheckInterval = time.Millisecond
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/projects",
+		func(w http.ResponseWriter, r *http.Request) {})
+
+	servertest.Mux.HandleFunc("/deploy",
+		func(w http.ResponseWriter, r *http.Request) {})
+
+	servertest.Mux.HandleFunc("/projects/deps/containers/db",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			fmt.Fprintf(w, `{"health": "down"}`)
+		})
+
+	var machine = &Machine{
+		Parallel: 1,
+		Timeout:  20 * time.Millisecond,
+	}
+
+	if err := machine.Setup("mocks/project-with-deps", []string{"db"}); err != nil {
+		t.Errorf("Unexpected error %v on linking", err)
+	}
+
+	machine.Run()
+
+	if len(machine.Errors.List) != 1 {
+		t.Fatalf("Expected one linking error, got %v instead", machine.Errors)
+	}
+
+	if _, ok := machine.Errors.List[0].Error.(workerpool.TimeoutError); !ok {
+		t.Errorf("Expected timeout error, got %v instead", machine.Errors.List[0].Error)
+	}
+
+	var results = machine.Results()
+
+	if len(results) != 1 || results[0].Name != "db" || results[0].Err == nil {
+		t.Errorf("Expected failed result for db, got %v instead", results)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+	healthCheckInterval = defaultHealthCheckInterval
+}
@@ -0,0 +1,75 @@
+package link
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/containers"
+)
+
+// Orphans gets the IDs of the containers linked on a project
+// that no longer exist on the local project directory
+func Orphans(projectID, projectPath string) ([]string, error) {
+	var dirs, err = containers.GetListFromDirectory(projectPath)
+
+	if err != nil {
+		return nil, errwrap.Wrapf("Can't find orphan containers: {{err}}", err)
+	}
+
+	var local = map[string]bool{}
+
+	for _, dir := range dirs {
+		c, err := containers.ReadRaw(filepath.Join(projectPath, dir))
+
+		if err != nil {
+			return nil, errwrap.Wrapf("Can't find orphan containers: {{err}}", err)
+		}
+
+		local[c.ID] = true
+	}
+
+	linked, err := containers.List(projectID)
+
+	if err != nil {
+		return nil, errwrap.Wrapf("Can't find orphan containers: {{err}}", err)
+	}
+
+	var orphans = []string{}
+
+	for id := range linked {
+		if !local[id] {
+			orphans = append(orphans, id)
+		}
+	}
+
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// Prune unlinks the orphan containers of a project
+// and returns the ones unlinked without errors
+func Prune(projectID string, orphans []string) (unlinked []string, err error) {
+	var es = Errors{
+		List: []ContainerError{},
+	}
+
+	for _, id := range orphans {
+		if err := containers.Unlink(projectID, id); err != nil {
+			es.List = append(es.List, ContainerError{
+				ContainerPath: id,
+				Error:         err,
+			})
+
+			continue
+		}
+
+		unlinked = append(unlinked, id)
+	}
+
+	if len(es.List) != 0 {
+		return unlinked, es
+	}
+
+	return unlinked, nil
+}
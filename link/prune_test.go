@@ -0,0 +1,98 @@
+package link
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/wedeploy/cli/configmock"
+	"github.com/wedeploy/cli/servertest"
+)
+
+func TestOrphans(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/projects/deps/containers",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			fmt.Fprintf(w, `{"db": {"id": "db"}, "old": {"id": "old"}, "cache": {"id": "cache"}}`)
+		})
+
+	var orphans, err = Orphans("deps", "mocks/project-with-deps")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = []string{"cache", "old"}
+
+	if !reflect.DeepEqual(orphans, want) {
+		t.Errorf("Wanted orphans %v, got %v instead", want, orphans)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestOrphansProjectNotFound(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/projects/deps/containers",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			w.WriteHeader(404)
+			fmt.Fprintf(w, `{"code": 404, "message": "Not Found"}`)
+		})
+
+	if _, err := Orphans("deps", "mocks/project-with-deps"); err == nil {
+		t.Errorf("Expected error, got nil instead")
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestPrune(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	var deleted = []string{}
+
+	servertest.Mux.HandleFunc("/deploy/deps/",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "DELETE" {
+				t.Errorf("Unexpected method %v", r.Method)
+			}
+
+			if r.URL.Path == "/deploy/deps/broken" {
+				w.Header().Set("Content-type", "application/json; charset=UTF-8")
+				w.WriteHeader(500)
+				fmt.Fprintf(w, `{"code": 500, "message": "Internal Server Error"}`)
+				return
+			}
+
+			deleted = append(deleted, r.URL.Path)
+		})
+
+	var unlinked, err = Prune("deps", []string{"cache", "broken", "old"})
+
+	if e, ok := err.(Errors); !ok || len(e.List) != 1 || e.List[0].ContainerPath != "broken" {
+		t.Errorf("Expected error unlinking broken, got %v instead", err)
+	}
+
+	if !reflect.DeepEqual(unlinked, []string{"cache", "old"}) {
+		t.Errorf("Expected cache and old to be unlinked, got %v instead", unlinked)
+	}
+
+	var want = []string{"/deploy/deps/cache", "/deploy/deps/old"}
+
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("Wanted requests %v, got %v instead", want, deleted)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
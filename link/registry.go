@@ -0,0 +1,133 @@
+package link
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/user"
+)
+
+// RegistryEntry is a container linked to the local infrastructure
+type RegistryEntry struct {
+	ProjectID     string `json:"projectId"`
+	ContainerID   string `json:"containerId"`
+	ProjectPath   string `json:"projectPath"`
+	ContainerPath string `json:"containerPath"`
+	Local         string `json:"local,omitempty"`
+}
+
+// Registry of the containers linked to the local infrastructure
+// it is used to link them again when the infrastructure is replaced
+type Registry struct {
+	Path    string
+	Entries []RegistryEntry
+}
+
+// GetRegistry loads the registry of linked containers from the user's home
+func GetRegistry() (*Registry, error) {
+	var r = &Registry{
+		Path: filepath.Join(user.GetHomeDir(), ".we_links"),
+	}
+
+	return r, r.Load()
+}
+
+// Load the registry (a missing registry file means nothing is linked)
+func (r *Registry) Load() error {
+	var content, err = ioutil.ReadFile(r.Path)
+
+	switch {
+	case os.IsNotExist(err):
+		r.Entries = []RegistryEntry{}
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Can't read linked containers registry: {{err}}", err)
+	}
+
+	if err = json.Unmarshal(content, &r.Entries); err != nil {
+		return errwrap.Wrapf("Can't decode linked containers registry: {{err}}", err)
+	}
+
+	return nil
+}
+
+// Save the registry
+func (r *Registry) Save() error {
+	var bin, err = json.MarshalIndent(r.Entries, "", "    ")
+
+	if err == nil {
+		err = ioutil.WriteFile(r.Path, bin, 0644)
+	}
+
+	if err != nil {
+		return errwrap.Wrapf("Can't save linked containers registry: {{err}}", err)
+	}
+
+	return nil
+}
+
+// Add a linked container, replacing any previous entry for it
+func (r *Registry) Add(entry RegistryEntry) {
+	r.RemoveLocal(entry.Local, entry.ProjectID, entry.ContainerID)
+	r.Entries = append(r.Entries, entry)
+
+	sort.Sort(registryEntries(r.Entries))
+}
+
+// Remove a linked container (or all containers of a project if containerID is empty)
+func (r *Registry) Remove(projectID, containerID string) {
+	r.RemoveLocal("", projectID, containerID)
+}
+
+// RemoveLocal removes a container linked to a named local infrastructure
+func (r *Registry) RemoveLocal(local, projectID, containerID string) {
+	var entries = []RegistryEntry{}
+
+	for _, e := range r.Entries {
+		if e.Local == local && e.ProjectID == projectID &&
+			(containerID == "" || e.ContainerID == containerID) {
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	r.Entries = entries
+}
+
+// Projects returns the linked containers grouped by project path
+func (r *Registry) Projects() map[string][]RegistryEntry {
+	var projects = map[string][]RegistryEntry{}
+
+	for _, e := range r.Entries {
+		projects[e.ProjectPath] = append(projects[e.ProjectPath], e)
+	}
+
+	return projects
+}
+
+type registryEntries []RegistryEntry
+
+func (re registryEntries) Len() int {
+	return len(re)
+}
+
+func (re registryEntries) Swap(i, j int) {
+	re[i], re[j] = re[j], re[i]
+}
+
+func (re registryEntries) Less(i, j int) bool {
+	if re[i].Local != re[j].Local {
+		return re[i].Local < re[j].Local
+	}
+
+	if re[i].ProjectID != re[j].ProjectID {
+		return re[i].ProjectID < re[j].ProjectID
+	}
+
+	return re[i].ContainerID < re[j].ContainerID
+}
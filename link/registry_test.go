@@ -0,0 +1,105 @@
+package link
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRegistryLoadNotExists(t *testing.T) {
+	var r = &Registry{
+		Path: "mocks/not-found",
+	}
+
+	if err := r.Load(); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if r.Entries == nil || len(r.Entries) != 0 {
+		t.Errorf("Expected registry to be empty, got %v instead", r.Entries)
+	}
+}
+
+func TestRegistryAddRemoveSave(t *testing.T) {
+	var dir, err = ioutil.TempDir(os.TempDir(), "we-registry")
+
+	if err != nil {
+		panic(err)
+	}
+
+	var r = &Registry{
+		Path: filepath.Join(dir, ".we_links"),
+	}
+
+	r.Add(RegistryEntry{ProjectID: "p", ContainerID: "web", ProjectPath: "/p", ContainerPath: "/p/web"})
+	r.Add(RegistryEntry{ProjectID: "p", ContainerID: "db", ProjectPath: "/p", ContainerPath: "/p/old-db"})
+	r.Add(RegistryEntry{ProjectID: "p", ContainerID: "db", ProjectPath: "/p", ContainerPath: "/p/db"})
+	r.Add(RegistryEntry{ProjectID: "a", ContainerID: "api", ProjectPath: "/a", ContainerPath: "/a/api"})
+
+	if err = r.Save(); err != nil {
+		t.Errorf("Expected no error saving registry, got %v instead", err)
+	}
+
+	var loaded = &Registry{
+		Path: r.Path,
+	}
+
+	if err = loaded.Load(); err != nil {
+		t.Errorf("Expected no error loading registry, got %v instead", err)
+	}
+
+	var want = []RegistryEntry{
+		RegistryEntry{ProjectID: "a", ContainerID: "api", ProjectPath: "/a", ContainerPath: "/a/api"},
+		RegistryEntry{ProjectID: "p", ContainerID: "db", ProjectPath: "/p", ContainerPath: "/p/db"},
+		RegistryEntry{ProjectID: "p", ContainerID: "web", ProjectPath: "/p", ContainerPath: "/p/web"},
+	}
+
+	if !reflect.DeepEqual(loaded.Entries, want) {
+		t.Errorf("Wanted entries %v, got %v instead", want, loaded.Entries)
+	}
+
+	var projects = loaded.Projects()
+
+	if len(projects) != 2 || len(projects["/p"]) != 2 || len(projects["/a"]) != 1 {
+		t.Errorf("Unexpected entries grouped by project: %v", projects)
+	}
+
+	loaded.Remove("p", "db")
+
+	if len(loaded.Entries) != 2 {
+		t.Errorf("Expected 2 entries after removing container, got %v instead", loaded.Entries)
+	}
+
+	loaded.Remove("p", "")
+
+	if !reflect.DeepEqual(loaded.Entries, want[:1]) {
+		t.Errorf("Expected only project a after removing project p, got %v instead", loaded.Entries)
+	}
+
+	if err = os.RemoveAll(dir); err != nil {
+		panic(err)
+	}
+}
+
+func TestRegistryLocals(t *testing.T) {
+	var r = &Registry{}
+
+	r.Add(RegistryEntry{ProjectID: "p", ContainerID: "web", ProjectPath: "/p"})
+	r.Add(RegistryEntry{ProjectID: "p", ContainerID: "web", ProjectPath: "/p", Local: "staging"})
+
+	if len(r.Entries) != 2 {
+		t.Errorf("Expected container linked on two local infrastructures, got %v instead", r.Entries)
+	}
+
+	r.RemoveLocal("staging", "p", "")
+
+	var want = []RegistryEntry{
+		RegistryEntry{ProjectID: "p", ContainerID: "web", ProjectPath: "/p"},
+	}
+
+	if !reflect.DeepEqual(r.Entries, want) {
+		t.Errorf("Wanted entries %v, got %v instead", want, r.Entries)
+	}
+}
@@ -0,0 +1,94 @@
+package link
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/wedeploy/cli/containers"
+)
+
+// Selection of the containers of a project to link
+type Selection struct {
+	// IDs of the containers (all containers if empty)
+	IDs []string
+	// Exclude patterns matching container IDs or directories
+	Exclude []string
+	// Tags of the containers (containers with any of them are selected)
+	Tags []string
+}
+
+// IsEmpty tells if the selection selects all containers
+func (s Selection) IsEmpty() bool {
+	return len(s.IDs) == 0 && len(s.Exclude) == 0 && len(s.Tags) == 0
+}
+
+// Select the directories of the containers of a project matching the selection
+func (s Selection) Select(projectPath string, dirs []string) ([]string, error) {
+	var selected = []string{}
+	var found = map[string]bool{}
+
+	for _, dir := range dirs {
+		var c, err = containers.ReadRaw(filepath.Join(projectPath, dir))
+
+		if err != nil {
+			return nil, fmt.Errorf("%v/ dir error: %v", dir, err)
+		}
+
+		found[c.ID] = true
+
+		if s.matches(dir, c) {
+			selected = append(selected, dir)
+		}
+	}
+
+	for _, id := range s.IDs {
+		if !found[id] {
+			return nil, fmt.Errorf("Container %v not found on project.", id)
+		}
+	}
+
+	return selected, nil
+}
+
+func (s Selection) matches(dir string, c *containers.Container) bool {
+	if len(s.IDs) != 0 && !inArray(c.ID, s.IDs) {
+		return false
+	}
+
+	if len(s.Tags) != 0 && !hasAnyTag(c, s.Tags) {
+		return false
+	}
+
+	for _, pattern := range s.Exclude {
+		if matchPattern(pattern, c.ID) || matchPattern(pattern, dir) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasAnyTag(c *containers.Container, tags []string) bool {
+	for _, tag := range c.Tags {
+		if inArray(tag, tags) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchPattern(pattern, name string) bool {
+	var matched, _ = filepath.Match(pattern, name)
+	return matched
+}
+
+func inArray(key string, haystack []string) bool {
+	for _, k := range haystack {
+		if key == k {
+			return true
+		}
+	}
+
+	return false
+}
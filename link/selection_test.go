@@ -0,0 +1,57 @@
+package link
+
+import (
+	"reflect"
+	"testing"
+)
+
+var selectionCases = []struct {
+	selection Selection
+	want      []string
+}{
+	{Selection{}, []string{"api", "db", "web"}},
+	{Selection{IDs: []string{"web", "db"}}, []string{"db", "web"}},
+	{Selection{Tags: []string{"backend"}}, []string{"api", "db"}},
+	{Selection{Tags: []string{"frontend", "data"}}, []string{"db", "web"}},
+	{Selection{Tags: []string{"backend"}, Exclude: []string{"d*"}}, []string{"api"}},
+	{Selection{Exclude: []string{"api", "web"}}, []string{"db"}},
+	{Selection{IDs: []string{"api"}, Tags: []string{"frontend"}}, []string{}},
+}
+
+func TestSelectionSelect(t *testing.T) {
+	var dirs = []string{"api", "db", "web"}
+
+	for _, c := range selectionCases {
+		var got, err = c.selection.Select("mocks/project-with-deps", dirs)
+
+		if err != nil {
+			t.Errorf("Expected no error for %+v, got %v instead", c.selection, err)
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Wanted %+v to select %v, got %v instead", c.selection, c.want, got)
+		}
+	}
+}
+
+func TestSelectionSelectNotFound(t *testing.T) {
+	var s = Selection{
+		IDs: []string{"api", "cdn"},
+	}
+
+	var _, err = s.Select("mocks/project-with-deps", []string{"api", "db", "web"})
+
+	if err == nil || err.Error() != "Container cdn not found on project." {
+		t.Errorf("Expected container not found error, got %v instead", err)
+	}
+}
+
+func TestSelectionIsEmpty(t *testing.T) {
+	if !(Selection{}).IsEmpty() {
+		t.Errorf("Expected empty selection")
+	}
+
+	if (Selection{Tags: []string{"backend"}}).IsEmpty() {
+		t.Errorf("Expected selection with tags to not be empty")
+	}
+}
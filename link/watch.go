@@ -0,0 +1,261 @@
+package link
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/hooks"
+	"github.com/wedeploy/cli/verbose"
+)
+
+// DefaultIgnorePatterns are the files ignored when watching containers files
+var DefaultIgnorePatterns = []string{
+	".git",
+	".hg",
+	".svn",
+	".DS_Store",
+	"node_modules",
+	"*.swp",
+	"*.swx",
+	"*~",
+	"#*#",
+}
+
+// DefaultDebounce is the time to wait for more changes before linking again
+var DefaultDebounce = 300 * time.Millisecond
+
+// FileWatcher links the containers again when their files change
+type FileWatcher struct {
+	Machine  *Machine
+	Ignore   []string
+	Debounce time.Duration
+	watcher  *fsnotify.Watcher
+	links    map[string]*Link
+	pending  map[string]bool
+	busy     map[string]time.Time
+	timer    *time.Timer
+	mutex    sync.Mutex
+	relink   func(*Link)
+	end      chan bool
+}
+
+// NewFileWatcher creates a file watcher for the containers of a machine
+func NewFileWatcher(m *Machine, ignore []string) *FileWatcher {
+	var fw = &FileWatcher{
+		Machine:  m,
+		Ignore:   append(append([]string{}, DefaultIgnorePatterns...), ignore...),
+		Debounce: DefaultDebounce,
+		links:    map[string]*Link{},
+		pending:  map[string]bool{},
+		busy:     map[string]time.Time{},
+		end:      make(chan bool, 1),
+	}
+
+	fw.relink = m.relink
+	return fw
+}
+
+// Start watching the files of the containers
+// (including the ones that failed to link, so they are linked when fixed)
+func (fw *FileWatcher) Start() (err error) {
+	if fw.watcher, err = fsnotify.NewWatcher(); err != nil {
+		return errwrap.Wrapf("Can't watch containers files: {{err}}", err)
+	}
+
+	for _, l := range fw.Machine.Links {
+		var dir = getAbsPath(l.ContainerPath)
+		fw.links[dir] = l
+
+		if err = fw.addRecursive(dir); err != nil {
+			return errwrap.Wrapf("Can't watch containers files: {{err}}", err)
+		}
+	}
+
+	go fw.watch()
+	return nil
+}
+
+// Stop watching the files
+func (fw *FileWatcher) Stop() error {
+	fw.end <- true
+
+	fw.mutex.Lock()
+
+	if fw.timer != nil {
+		fw.timer.Stop()
+	}
+
+	fw.mutex.Unlock()
+	return fw.watcher.Close()
+}
+
+func (fw *FileWatcher) addRecursive(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		if path != dir && fw.isIgnored(path) {
+			return filepath.SkipDir
+		}
+
+		verbose.Debug("Watching " + path)
+		return fw.watcher.Add(path)
+	})
+}
+
+func (fw *FileWatcher) watch() {
+	for {
+		select {
+		case event := <-fw.watcher.Events:
+			fw.handle(event)
+		case err := <-fw.watcher.Errors:
+			fmt.Fprintf(fw.Machine.FErrStream, "File watcher error: %v\n", err)
+		case <-fw.end:
+			return
+		}
+	}
+}
+
+func (fw *FileWatcher) handle(event fsnotify.Event) {
+	if fw.isIgnored(event.Name) {
+		return
+	}
+
+	if event.Op&fsnotify.Create == fsnotify.Create {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err := fw.addRecursive(event.Name); err != nil {
+				verbose.Debug("Can't watch new directory " + event.Name + ": " + err.Error())
+			}
+		}
+	}
+
+	var dir, ok = fw.findContainerDir(event.Name)
+
+	if !ok {
+		return
+	}
+
+	fw.mutex.Lock()
+	defer fw.mutex.Unlock()
+
+	// changes made while a container is built and linked (or right after it)
+	// are due to its build hooks, so they are ignored to avoid relinking forever
+	if t, ok := fw.busy[dir]; ok && (t.IsZero() || time.Since(t) < fw.Debounce) {
+		return
+	}
+
+	verbose.Debug("File " + event.Name + " changed: " + event.Op.String())
+	fw.pending[dir] = true
+
+	if fw.timer != nil {
+		fw.timer.Stop()
+	}
+
+	fw.timer = time.AfterFunc(fw.Debounce, fw.flush)
+}
+
+func (fw *FileWatcher) flush() {
+	fw.mutex.Lock()
+	var dirs = []string{}
+
+	for dir := range fw.pending {
+		dirs = append(dirs, dir)
+		fw.busy[dir] = time.Time{}
+	}
+
+	fw.pending = map[string]bool{}
+	fw.mutex.Unlock()
+
+	// build hooks change the working directory, so don't run them in parallel
+	for _, dir := range dirs {
+		fw.relink(fw.links[dir])
+
+		fw.mutex.Lock()
+		fw.busy[dir] = time.Now()
+		fw.mutex.Unlock()
+	}
+}
+
+func (fw *FileWatcher) findContainerDir(path string) (string, bool) {
+	for dir := range fw.links {
+		if path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator)) {
+			return dir, true
+		}
+	}
+
+	return "", false
+}
+
+func (fw *FileWatcher) isIgnored(path string) bool {
+	var dir, _ = fw.findContainerDir(path)
+	var rel, err = filepath.Rel(dir, path)
+
+	if dir == "" || err != nil {
+		rel = path
+	}
+
+	var parts = strings.Split(filepath.ToSlash(rel), "/")
+
+	for _, pattern := range fw.Ignore {
+		if matched, _ := filepath.Match(pattern, filepath.ToSlash(rel)); matched {
+			return true
+		}
+
+		for _, part := range parts {
+			if matched, _ := filepath.Match(pattern, part); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// relink builds and links a container again after its files changed
+func (m *Machine) relink(l *Link) {
+	var c, err = containers.Read(l.ContainerPath)
+
+	if err == nil {
+		l.Container = c
+		err = c.Hooks.Run(hooks.Build, l.ContainerPath)
+	}
+
+	if err == nil {
+		err = m.link(l)
+	}
+
+	if err != nil {
+		m.addError(l.ContainerPath, err)
+		m.printf(m.FErrStream, "%v/ dir error: %v\n", l.ContainerPath, err)
+		return
+	}
+
+	m.clearErrors(l.ContainerPath)
+	m.printf(outStream, "Container %v changed and was linked again.\n", c.ID)
+}
+
+func (m *Machine) clearErrors(dir string) {
+	m.ErrorsMutex.Lock()
+	var list = []ContainerError{}
+
+	for _, e := range m.Errors.List {
+		if e.ContainerPath != dir {
+			list = append(list, e)
+		}
+	}
+
+	m.Errors.List = list
+	m.ErrorsMutex.Unlock()
+}
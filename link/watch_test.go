@@ -0,0 +1,135 @@
+package link
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wedeploy/cli/list"
+)
+
+func TestFileWatcherIsIgnored(t *testing.T) {
+	var fw = NewFileWatcher(&Machine{}, []string{"build", "*.log"})
+	fw.links["/p/web"] = &Link{ContainerPath: "/p/web"}
+
+	var cases = map[string]bool{
+		"/p/web/index.html":              false,
+		"/p/web/src/main.go":             false,
+		"/p/web/.git/HEAD":               true,
+		"/p/web/node_modules/x/index.js": true,
+		"/p/web/build/app.js":            true,
+		"/p/web/logs/server.log":         true,
+		"/p/web/.main.go.swp":            true,
+		"/p/web/main.go~":                true,
+	}
+
+	for path, want := range cases {
+		if got := fw.isIgnored(path); got != want {
+			t.Errorf("Wanted isIgnored(%v) to be %v, got %v instead", path, want, got)
+		}
+	}
+}
+
+func TestFileWatcherFindContainerDir(t *testing.T) {
+	var fw = NewFileWatcher(&Machine{}, nil)
+	fw.links["/p/web"] = &Link{}
+	fw.links["/p/web2"] = &Link{}
+
+	if dir, ok := fw.findContainerDir("/p/web2/index.html"); !ok || dir != "/p/web2" {
+		t.Errorf("Expected container dir to be /p/web2, got %v instead", dir)
+	}
+
+	if dir, ok := fw.findContainerDir("/p/web"); !ok || dir != "/p/web" {
+		t.Errorf("Expected container dir to be /p/web, got %v instead", dir)
+	}
+
+	if _, ok := fw.findContainerDir("/p/other/index.html"); ok {
+		t.Errorf("Expected file outside containers to not be found")
+	}
+}
+
+func TestFileWatcherRelink(t *testing.T) {
+	var dir, err = ioutil.TempDir(os.TempDir(), "we-watch")
+
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			panic(err)
+		}
+	}()
+
+	var web = &Link{ContainerPath: filepath.Join(dir, "web")}
+	var api = &Link{ContainerPath: filepath.Join(dir, "api")}
+
+	for _, l := range []*Link{web, api} {
+		if err = os.MkdirAll(filepath.Join(l.ContainerPath, "node_modules"), 0755); err != nil {
+			panic(err)
+		}
+	}
+
+	var fw = NewFileWatcher(&Machine{Links: []*Link{web, api}}, nil)
+	fw.Debounce = 50 * time.Millisecond
+
+	var relinked = make(chan *Link, 10)
+	fw.relink = func(l *Link) {
+		relinked <- l
+	}
+
+	if err = fw.Start(); err != nil {
+		panic(err)
+	}
+
+	writeFile(filepath.Join(api.ContainerPath, "node_modules", "ignored.js"))
+	writeFile(filepath.Join(web.ContainerPath, "index.html"))
+	writeFile(filepath.Join(web.ContainerPath, "style.css"))
+
+	select {
+	case l := <-relinked:
+		if l != web {
+			t.Errorf("Expected web container to be linked again, got %v instead", l.ContainerPath)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected web container to be linked again after changes")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if len(relinked) != 0 {
+		t.Errorf("Expected changes to be debounced into a single link")
+	}
+
+	if err = fw.Stop(); err != nil {
+		t.Errorf("Expected no error stopping watcher, got %v instead", err)
+	}
+}
+
+func writeFile(path string) {
+	if err := ioutil.WriteFile(path, []byte("changed"), 0644); err != nil {
+		panic(err)
+	}
+}
+
+func TestMachinePrintfWatching(t *testing.T) {
+	var b = &bytes.Buffer{}
+	var m = &Machine{}
+
+	m.printf(b, "Container %v changed and was linked again.\n", "web")
+
+	if want := "Container web changed and was linked again.\n"; b.String() != want {
+		t.Errorf("Wanted %v, got %v instead", want, b.String())
+	}
+
+	b.Reset()
+	m.Watcher = list.NewWatcher(list.New(list.Filter{}))
+	m.printf(b, "Container %v changed and was linked again.\n", "web")
+
+	if b.Len() != 0 {
+		t.Errorf("Expected message to go through the watcher, got %v instead", b.String())
+	}
+}
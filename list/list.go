@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -248,6 +249,8 @@ type Watcher struct {
 	StopCondition   (func() bool)
 	livew           *uilive.Writer
 	End             chan bool
+	messages        []string
+	messagesMutex   sync.Mutex
 }
 
 // Start for Watcher
@@ -273,9 +276,36 @@ func (w *Watcher) Start() {
 	<-w.End
 }
 
+// maxWatcherMessages is how many of the latest messages are printed below the list
+var maxWatcherMessages = 10
+
+// Printf adds a message to be printed below the list
+// (writing to the terminal directly while watching garbles the list)
+func (w *Watcher) Printf(format string, a ...interface{}) {
+	w.messagesMutex.Lock()
+	w.messages = append(w.messages, fmt.Sprintf(format, a...))
+
+	if len(w.messages) > maxWatcherMessages {
+		w.messages = w.messages[len(w.messages)-maxWatcherMessages:]
+	}
+
+	w.messagesMutex.Unlock()
+}
+
+func (w *Watcher) printMessages() {
+	w.messagesMutex.Lock()
+
+	for _, m := range w.messages {
+		fmt.Fprint(w.livew, m)
+	}
+
+	w.messagesMutex.Unlock()
+}
+
 func (w *Watcher) watch() {
 p:
 	w.List.Print()
+	w.printMessages()
 
 	if err := w.livew.Flush(); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
@@ -0,0 +1,106 @@
+package overlay
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/definition"
+	"github.com/wedeploy/cli/verbose"
+)
+
+var profileRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// IsValidProfile tells if a profile name is valid
+func IsValidProfile(profile string) bool {
+	return profileRegex.MatchString(profile)
+}
+
+// Profile gets the active profile (empty if none)
+func Profile() string {
+	if config.Context == nil {
+		return ""
+	}
+
+	return config.Context.Profile
+}
+
+// Name gets the name of the overlay of a definition for a profile
+// (i.e., container.staging for container and staging)
+func Name(name, profile string) string {
+	return name + "." + profile
+}
+
+// Read a definition (i.e., container for container.json or container.yaml)
+// of a directory as JSON, merging the overlay of the profile over it when it exists
+func Read(dir, name, profile string) ([]byte, error) {
+	var file, err = definition.Find(dir, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := definition.Read(file)
+
+	if err != nil || profile == "" {
+		return content, err
+	}
+
+	var overlayName = Name(name, profile)
+	overlayFile, err := definition.Find(dir, overlayName)
+
+	switch {
+	case os.IsNotExist(err):
+		verbose.Debug("No " + overlayName + " overlay for profile " + profile + " on " + dir)
+		return content, nil
+	case err != nil:
+		return nil, err
+	}
+
+	overlayContent, err := definition.Read(overlayFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var base, over map[string]interface{}
+
+	if err = json.Unmarshal(content, &base); err != nil {
+		return nil, errwrap.Wrapf("Can't read "+filepath.Base(file)+": {{err}}", err)
+	}
+
+	if err = json.Unmarshal(overlayContent, &over); err != nil {
+		return nil, errwrap.Wrapf("Can't read "+filepath.Base(overlayFile)+": {{err}}", err)
+	}
+
+	return json.MarshalIndent(Merge(base, over), "", "    ")
+}
+
+// Merge an overlay over a base document: objects are merged recursively,
+// other values are replaced and null values remove the field
+func Merge(base, overlay map[string]interface{}) map[string]interface{} {
+	var merged = map[string]interface{}{}
+
+	for k, v := range base {
+		merged[k] = v
+	}
+
+	for k, v := range overlay {
+		var bm, isBaseMap = merged[k].(map[string]interface{})
+		var om, isOverlayMap = v.(map[string]interface{})
+
+		switch {
+		case v == nil:
+			delete(merged, k)
+		case isBaseMap && isOverlayMap:
+			merged[k] = Merge(bm, om)
+		default:
+			merged[k] = v
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,128 @@
+package overlay
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/configmock"
+)
+
+func TestIsValidProfile(t *testing.T) {
+	var cases = map[string]bool{
+		"staging":   true,
+		"prod-eu_1": true,
+		"":          false,
+		"../prod":   false,
+		"a.b":       false,
+	}
+
+	for profile, want := range cases {
+		if got := IsValidProfile(profile); got != want {
+			t.Errorf("Wanted IsValidProfile(%q) = %v, got %v instead", profile, want, got)
+		}
+	}
+}
+
+func TestProfile(t *testing.T) {
+	var defaultContext = config.Context
+	config.Context = nil
+
+	if p := Profile(); p != "" {
+		t.Errorf("Expected no profile without context, got %v instead", p)
+	}
+
+	configmock.Setup()
+	config.Context.Profile = "staging"
+
+	if p := Profile(); p != "staging" {
+		t.Errorf("Expected profile to be staging, got %v instead", p)
+	}
+
+	configmock.Teardown()
+	config.Context = defaultContext
+}
+
+func TestName(t *testing.T) {
+	if n := Name("container", "staging"); n != "container.staging" {
+		t.Errorf("Expected overlay container.staging, got %v instead", n)
+	}
+}
+
+func TestReadNoProfile(t *testing.T) {
+	var content, err = Read("mocks", "container", "")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	want, err := ioutil.ReadFile("mocks/container.json")
+
+	if err != nil {
+		panic(err)
+	}
+
+	if string(content) != string(want) {
+		t.Errorf("Expected base file content, got %v instead", string(content))
+	}
+}
+
+func TestReadMissingOverlay(t *testing.T) {
+	var content, err = Read("mocks", "container", "prod")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	want, err := ioutil.ReadFile("mocks/container.json")
+
+	if err != nil {
+		panic(err)
+	}
+
+	if string(content) != string(want) {
+		t.Errorf("Expected base file content, got %v instead", string(content))
+	}
+}
+
+func TestRead(t *testing.T) {
+	var content, err = Read("mocks", "container", "staging")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var got map[string]interface{}
+
+	if err = json.Unmarshal(content, &got); err != nil {
+		t.Fatalf("Expected valid JSON, got %v instead", err)
+	}
+
+	var want = map[string]interface{}{
+		"id":        "email",
+		"instances": 3.0,
+		"env": map[string]interface{}{
+			"FROM": "noreply@example.com",
+			"HOST": "smtp.staging.example.com",
+		},
+		"tags": []interface{}{"mail"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted %v, got %v instead", want, got)
+	}
+}
+
+func TestReadCorruptedOverlay(t *testing.T) {
+	if _, err := Read("mocks/corrupted", "container", "staging"); err == nil {
+		t.Errorf("Expected error, got nil instead")
+	}
+}
+
+func TestReadNotFound(t *testing.T) {
+	if _, err := Read("mocks/not-found", "container", "staging"); err == nil {
+		t.Errorf("Expected error, got nil instead")
+	}
+}
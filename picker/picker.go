@@ -0,0 +1,305 @@
+package picker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/mitchellh/go-wordwrap"
+	"github.com/wedeploy/cli/color"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Item to pick
+type Item struct {
+	Label       string
+	Category    string
+	Description string
+}
+
+var (
+	// ErrNoRawMode is used when the terminal doesn't support raw mode
+	ErrNoRawMode = errors.New("Terminal doesn't support raw mode")
+
+	// ErrCanceled is used when the user cancels picking an item
+	ErrCanceled = errors.New("Canceled")
+
+	inStream  io.Reader = os.Stdin
+	outStream io.Writer = os.Stdout
+)
+
+// maxVisible is the maximum number of items shown at the same time
+const maxVisible = 10
+
+// keys
+const (
+	keyNone = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyBackspace
+	keyTab
+	keyCancel
+	keyRune
+)
+
+type picker struct {
+	items      []Item
+	categories []string
+	category   int
+	query      string
+	cursor     int
+	filtered   []int
+	lines      int
+}
+
+// Pick an item with an interactive fuzzy-search selector:
+// type to search, up and down to move, tab to filter by category
+// and enter to select. It returns the index of the picked item.
+func Pick(title string, items []Item) (int, error) {
+	var fd = int(os.Stdin.Fd())
+
+	if !terminal.IsTerminal(fd) {
+		return -1, ErrNoRawMode
+	}
+
+	var state, err = terminal.MakeRaw(fd)
+
+	if err != nil {
+		return -1, ErrNoRawMode
+	}
+
+	defer func() {
+		if er := terminal.Restore(fd, state); er != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", er)
+		}
+	}()
+
+	fmt.Fprintf(outStream, "%v\r\n", title)
+	return newPicker(items).run(bufio.NewReader(inStream))
+}
+
+func newPicker(items []Item) *picker {
+	var p = &picker{
+		items:      items,
+		categories: []string{""},
+	}
+
+	var found = map[string]bool{}
+
+	for _, i := range items {
+		if i.Category != "" && !found[i.Category] {
+			found[i.Category] = true
+			p.categories = append(p.categories, i.Category)
+		}
+	}
+
+	p.filter()
+	return p
+}
+
+func (p *picker) run(r *bufio.Reader) (int, error) {
+	for {
+		p.render()
+
+		var key, c, err = readKey(r)
+
+		if err != nil {
+			return -1, err
+		}
+
+		var done bool
+
+		if done, err = p.handle(key, c); done || err != nil {
+			p.clear()
+			return p.selected(), err
+		}
+	}
+}
+
+func (p *picker) selected() int {
+	if len(p.filtered) == 0 {
+		return -1
+	}
+
+	return p.filtered[p.cursor]
+}
+
+// handle a key, telling if an item was selected
+func (p *picker) handle(key int, c rune) (bool, error) {
+	switch key {
+	case keyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case keyDown:
+		if p.cursor < len(p.filtered)-1 {
+			p.cursor++
+		}
+	case keyTab:
+		p.category = (p.category + 1) % len(p.categories)
+		p.filter()
+	case keyBackspace:
+		if len(p.query) != 0 {
+			var rs = []rune(p.query)
+			p.query = string(rs[:len(rs)-1])
+			p.filter()
+		}
+	case keyRune:
+		p.query += string(c)
+		p.filter()
+	case keyEnter:
+		return len(p.filtered) != 0, nil
+	case keyCancel:
+		return false, ErrCanceled
+	}
+
+	return false, nil
+}
+
+func (p *picker) filter() {
+	p.filtered = []int{}
+	p.cursor = 0
+
+	for n, i := range p.items {
+		var category = p.categories[p.category]
+
+		if category != "" && i.Category != category {
+			continue
+		}
+
+		if Match(p.query, i.Label+" "+i.Category) {
+			p.filtered = append(p.filtered, n)
+		}
+	}
+}
+
+// Match tells if all characters of the query appear in order on the text,
+// ignoring case
+func Match(query, text string) bool {
+	var t = []rune(strings.ToLower(text))
+	var pos = 0
+
+	for _, q := range strings.ToLower(query) {
+		if unicode.IsSpace(q) {
+			continue
+		}
+
+		for pos < len(t) && t[pos] != q {
+			pos++
+		}
+
+		if pos == len(t) {
+			return false
+		}
+
+		pos++
+	}
+
+	return true
+}
+
+func (p *picker) render() {
+	p.clear()
+
+	var lines = []string{}
+	var category = p.categories[p.category]
+
+	if category == "" {
+		category = "all"
+	}
+
+	lines = append(lines, fmt.Sprintf("Search: %v%v",
+		p.query, color.Format(color.FgHiBlack, " (category: %v, tab to change)", category)))
+
+	var first = 0
+
+	if p.cursor >= maxVisible {
+		first = p.cursor - maxVisible + 1
+	}
+
+	for n := first; n < len(p.filtered) && n < first+maxVisible; n++ {
+		var i = p.items[p.filtered[n]]
+		var line = fmt.Sprintf("  %v %v", i.Label, color.Format(color.FgHiBlack, i.Category))
+
+		if n == p.cursor {
+			line = color.Format(color.FgCyan, "> ") + line[2:]
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(p.filtered) == 0 {
+		lines = append(lines, "  No matches.")
+	} else if d := p.items[p.selected()].Description; d != "" {
+		lines = append(lines, "")
+
+		for _, l := range strings.Split(wordwrap.WrapString(d, 80), "\n") {
+			lines = append(lines, color.Format(color.FgHiBlack, l))
+		}
+	}
+
+	fmt.Fprintf(outStream, "%v\r\n", strings.Join(lines, "\r\n"))
+	p.lines = len(lines)
+}
+
+// clear the lines previously rendered
+func (p *picker) clear() {
+	if p.lines != 0 {
+		fmt.Fprintf(outStream, "\033[%dA\r\033[J", p.lines)
+		p.lines = 0
+	}
+}
+
+func readKey(r *bufio.Reader) (key int, c rune, err error) {
+	if c, _, err = r.ReadRune(); err != nil {
+		return keyNone, c, err
+	}
+
+	switch c {
+	case '\r', '\n':
+		return keyEnter, c, nil
+	case 127, '\b':
+		return keyBackspace, c, nil
+	case '\t':
+		return keyTab, c, nil
+	case 3, 4:
+		return keyCancel, c, nil
+	case 27:
+		return readEscape(r)
+	}
+
+	if unicode.IsPrint(c) {
+		return keyRune, c, nil
+	}
+
+	return keyNone, c, nil
+}
+
+// readEscape reads the arrow keys escape sequences (ESC [ A and ESC [ B)
+func readEscape(r *bufio.Reader) (key int, c rune, err error) {
+	if r.Buffered() == 0 {
+		return keyCancel, 27, nil
+	}
+
+	if c, _, err = r.ReadRune(); err != nil || c != '[' {
+		return keyNone, c, err
+	}
+
+	if c, _, err = r.ReadRune(); err != nil {
+		return keyNone, c, err
+	}
+
+	switch c {
+	case 'A':
+		return keyUp, c, nil
+	case 'B':
+		return keyDown, c, nil
+	}
+
+	return keyNone, c, nil
+}
@@ -0,0 +1,201 @@
+package picker
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/wedeploy/cli/color"
+)
+
+var items = []Item{
+	{
+		Label:       "Node.js (wedeploy/nodejs)",
+		Category:    "runtime",
+		Description: "Node.js runtime",
+	},
+	{
+		Label:       "Elasticsearch (wedeploy/elasticsearch)",
+		Category:    "database",
+		Description: "Search and analytics engine",
+	},
+	{
+		Label:       "Static hosting (wedeploy/hosting)",
+		Category:    "runtime",
+		Description: "Static files server",
+	},
+}
+
+func init() {
+	color.NoColor = true
+}
+
+func TestMatch(t *testing.T) {
+	var cases = []struct {
+		query string
+		text  string
+		want  bool
+	}{
+		{"", "anything", true},
+		{"node", "Node.js", true},
+		{"njs", "Node.js", true},
+		{"es", "Elasticsearch", true},
+		{"sj", "Node.js", false},
+		{"nodejs!", "Node.js", false},
+		{"static host", "Static hosting", true},
+	}
+
+	for _, c := range cases {
+		if got := Match(c.query, c.text); got != c.want {
+			t.Errorf("Expected Match(%q, %q) = %v, got %v instead", c.query, c.text, c.want, got)
+		}
+	}
+}
+
+func TestNewPickerCategories(t *testing.T) {
+	var p = newPicker(items)
+	var want = []string{"", "runtime", "database"}
+
+	if strings.Join(p.categories, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected categories %v, got %v instead", want, p.categories)
+	}
+
+	if len(p.filtered) != len(items) {
+		t.Errorf("Expected all items to be listed, got %v instead", p.filtered)
+	}
+}
+
+func TestHandleFilter(t *testing.T) {
+	var p = newPicker(items)
+
+	for _, c := range "hosting" {
+		if _, err := p.handle(keyRune, c); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+
+	if len(p.filtered) != 1 || p.selected() != 2 {
+		t.Errorf("Expected only item 2 to match, got %v instead", p.filtered)
+	}
+
+	for range "hosting" {
+		if _, err := p.handle(keyBackspace, 0); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+
+	if p.query != "" || len(p.filtered) != len(items) {
+		t.Errorf("Expected empty query to list all items, got %v instead", p.filtered)
+	}
+}
+
+func TestHandleCategory(t *testing.T) {
+	var p = newPicker(items)
+
+	if _, err := p.handle(keyTab, 0); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if len(p.filtered) != 2 || p.filtered[0] != 0 || p.filtered[1] != 2 {
+		t.Errorf("Expected runtime items, got %v instead", p.filtered)
+	}
+
+	p.handle(keyTab, 0)
+	p.handle(keyTab, 0)
+
+	if len(p.filtered) != len(items) {
+		t.Errorf("Expected category filter to cycle back to all, got %v instead", p.filtered)
+	}
+}
+
+func TestHandleMove(t *testing.T) {
+	var p = newPicker(items)
+
+	p.handle(keyUp, 0)
+
+	if p.cursor != 0 {
+		t.Errorf("Expected cursor to stay on top, got %v instead", p.cursor)
+	}
+
+	p.handle(keyDown, 0)
+	p.handle(keyDown, 0)
+	p.handle(keyDown, 0)
+
+	if p.cursor != 2 {
+		t.Errorf("Expected cursor to stay on bottom, got %v instead", p.cursor)
+	}
+
+	var done, err = p.handle(keyEnter, 0)
+
+	if !done || err != nil || p.selected() != 2 {
+		t.Errorf("Expected item 2 to be selected, got %v (%v, %v) instead", p.selected(), done, err)
+	}
+}
+
+func TestHandleNoMatches(t *testing.T) {
+	var p = newPicker(items)
+
+	p.handle(keyRune, 'x')
+	p.handle(keyRune, 'y')
+
+	var done, err = p.handle(keyEnter, 0)
+
+	if done || err != nil || p.selected() != -1 {
+		t.Errorf("Expected nothing to be selected, got %v (%v, %v) instead", p.selected(), done, err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	var defaultOutStream = outStream
+	var bufOutStream bytes.Buffer
+	outStream = &bufOutStream
+
+	var p = newPicker(items)
+	var index, err = p.run(bufio.NewReader(strings.NewReader("st\x1b[A\x1b[B\r")))
+
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if index != 1 {
+		t.Errorf("Expected item 1 to be picked, got %v instead", index)
+	}
+
+	var out = bufOutStream.String()
+
+	if !strings.Contains(out, "Search: st (category: all, tab to change)") {
+		t.Errorf("Expected search line to be rendered, got %v instead", out)
+	}
+
+	if !strings.Contains(out, "> Elasticsearch (wedeploy/elasticsearch) database") {
+		t.Errorf("Expected highlighted item to be rendered, got %v instead", out)
+	}
+
+	if !strings.Contains(out, "Search and analytics engine") {
+		t.Errorf("Expected description preview, got %v instead", out)
+	}
+
+	outStream = defaultOutStream
+}
+
+func TestRunCancel(t *testing.T) {
+	var defaultOutStream = outStream
+	outStream = &bytes.Buffer{}
+
+	var p = newPicker(items)
+	var _, err = p.run(bufio.NewReader(strings.NewReader("no\x03")))
+
+	if err != ErrCanceled {
+		t.Errorf("Expected error to be %v, got %v instead", ErrCanceled, err)
+	}
+
+	_, err = newPicker(items).run(bufio.NewReader(strings.NewReader("no")))
+
+	if err != io.EOF {
+		t.Errorf("Expected error to be %v, got %v instead", io.EOF, err)
+	}
+
+	outStream = defaultOutStream
+}
@@ -1,16 +1,19 @@
 package projects
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/wedeploy/api-go"
 	"github.com/wedeploy/cli/apihelper"
 	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/definition"
+	"github.com/wedeploy/cli/overlay"
 	"github.com/wedeploy/cli/verbose"
 	"github.com/wedeploy/cli/verbosereq"
 )
@@ -39,8 +42,10 @@ var (
 )
 
 // Create a project on WeDeploy
+// (the project.<profile>.json overlay of the active profile is merged over the definition)
 func Create(filename string) error {
-	var file, err = os.Open(filename)
+	var dir, name = filepath.Split(filename)
+	var content, err = overlay.Read(dir, strings.TrimSuffix(name, filepath.Ext(name)), overlay.Profile())
 
 	if err != nil {
 		return err
@@ -51,7 +56,7 @@ func Create(filename string) error {
 
 	var req = apihelper.URL("/projects")
 	apihelper.Auth(req)
-	req.Body(file)
+	req.Body(bytes.NewReader(content))
 
 	return apihelper.Validate(req, req.Post())
 }
@@ -68,9 +73,10 @@ func List() (list []Project, err error) {
 	return list, err
 }
 
-// Read a project directory properties (defined by a project.json on it)
+// Read a project directory properties (defined by a project.json or project.yaml on it)
+// merging the project.<profile>.json overlay of the active profile over it
 func Read(path string) (*Project, error) {
-	var content, err = ioutil.ReadFile(filepath.Join(path, "project.json"))
+	var content, err = overlay.Read(path, "project", overlay.Profile())
 	var data Project
 
 	if err != nil {
@@ -105,7 +111,7 @@ func Restart(id string) error {
 
 // SetAuth sets a project authentication permissions
 func SetAuth(id, filename string) error {
-	var file, err = os.Open(filename)
+	var content, err = definition.Read(filename)
 
 	if err != nil {
 		return err
@@ -116,7 +122,7 @@ func SetAuth(id, filename string) error {
 
 	var req = apihelper.URL("/projects/" + id + "/auth")
 	apihelper.Auth(req)
-	req.Body(file)
+	req.Body(bytes.NewReader(content))
 
 	return apihelper.Validate(req, req.Put())
 }
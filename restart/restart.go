@@ -0,0 +1,282 @@
+package restart
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/verbose"
+	"github.com/wedeploy/cli/workerpool"
+)
+
+// DefaultTimeout is how long to wait for a restarted instance or container to be up
+var DefaultTimeout = 5 * time.Minute
+
+var checkInterval = time.Second
+
+var outStream io.Writer = os.Stdout
+
+// Instances gets the UIDs of the running instances of a container
+// (the logs might still have instances that are gone)
+func Instances(projectID, containerID string) ([]string, error) {
+	var list, err = containers.Instances(projectID, containerID)
+
+	if err != nil {
+		return nil, errwrap.Wrapf("Can't get instances of container "+containerID+": {{err}}", err)
+	}
+
+	var found = map[string]bool{}
+	var instances = []string{}
+
+	for _, i := range list {
+		if i.UID != "" && !found[i.UID] {
+			found[i.UID] = true
+			instances = append(instances, i.UID)
+		}
+	}
+
+	sort.Strings(instances)
+	return instances, nil
+}
+
+// WaitRestarted waits for a restarted instance of a container to be gone
+// and replaced by a new instance that is up, returning the new instance UID
+// the known instances (i.e., the ones not restarted) are not replacements
+func WaitRestarted(projectID, containerID, instanceUID string,
+	known []string, timeout time.Duration) (string, error) {
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	var r, ok = waitReplaced(projectID, containerID, []string{instanceUID}, known, timeout)
+
+	if !ok {
+		return "", getWaitRestartedError(instanceUID, containerID, r.gone, timeout)
+	}
+
+	return r.uid, nil
+}
+
+// WaitContainerRestarted waits for the instances of a restarted container
+// to be gone and replaced by new instances that are up
+func WaitContainerRestarted(projectID, containerID string, instances []string, timeout time.Duration) error {
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	var r, ok = waitReplaced(projectID, containerID, instances, nil, timeout)
+
+	if !ok {
+		return getWaitContainerRestartedError(containerID, r.gone, timeout)
+	}
+
+	return nil
+}
+
+// IsRestarted checks if the instances of a restarted container
+// are gone and replaced by new instances that are up
+func IsRestarted(projectID, containerID string, instances []string) (bool, error) {
+	var list, err = containers.Instances(projectID, containerID)
+
+	if err != nil {
+		return false, err
+	}
+
+	return checkReplaced(list, instances, nil).done(), nil
+}
+
+// replacement is the state of the instances of a restarted container
+type replacement struct {
+	// gone is true when the restarted instances are gone
+	gone bool
+
+	// uid of a new instance (empty if there is none yet)
+	uid string
+
+	// up is true when all new instances are up
+	up bool
+}
+
+func (r replacement) done() bool {
+	return r.gone && r.uid != "" && r.up
+}
+
+// waitReplaced waits for the restarted instances of a container to be gone
+// and replaced by new instances (neither restarted nor known) that are up
+func waitReplaced(projectID, containerID string,
+	restarted, known []string, timeout time.Duration) (r replacement, ok bool) {
+	var deadline = time.Now().Add(timeout)
+
+	for {
+		var list, err = containers.Instances(projectID, containerID)
+
+		if err != nil {
+			verbose.Debug("Can't get container " + containerID + " instances: " + err.Error())
+		} else {
+			r = checkReplaced(list, restarted, known)
+		}
+
+		if r.done() {
+			return r, true
+		}
+
+		if time.Now().After(deadline) {
+			return r, false
+		}
+
+		time.Sleep(checkInterval)
+	}
+}
+
+func checkReplaced(list []containers.Instance, restarted, known []string) replacement {
+	var old = map[string]bool{}
+	var r = replacement{
+		gone: true,
+		up:   true,
+	}
+
+	for _, uid := range known {
+		old[uid] = true
+	}
+
+	for _, uid := range restarted {
+		old[uid] = true
+	}
+
+	for _, i := range list {
+		switch {
+		case contains(restarted, i.UID):
+			r.gone = false
+		case !old[i.UID]:
+			r.uid = i.UID
+			r.up = r.up && i.Health == "up"
+		}
+	}
+
+	return r
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getWaitRestartedError(instanceUID, containerID string, gone bool, timeout time.Duration) error {
+	if !gone {
+		return fmt.Errorf("Instance %v of container %v didn't restart after %v",
+			instanceUID, containerID, timeout)
+	}
+
+	return fmt.Errorf("Instance %v of container %v is not up after %v",
+		instanceUID, containerID, timeout)
+}
+
+func getWaitContainerRestartedError(containerID string, gone bool, timeout time.Duration) error {
+	if !gone {
+		return fmt.Errorf("Container %v didn't restart after %v", containerID, timeout)
+	}
+
+	return fmt.Errorf("Container %v is not up after %v", containerID, timeout)
+}
+
+// Rolling restarts the instances of a container one at a time,
+// waiting for each restarted instance to be up before restarting the next one
+// and aborting on the first failure
+func Rolling(projectID, containerID string, timeout time.Duration) error {
+	var c, err = containers.Get(projectID, containerID)
+
+	if err != nil {
+		return err
+	}
+
+	instances, err := Instances(projectID, containerID)
+
+	if err != nil {
+		return err
+	}
+
+	if len(instances) == 0 {
+		return fmt.Errorf("Can't find instances of container %v", containerID)
+	}
+
+	if c.Instances != 0 && len(instances) != c.Instances {
+		fmt.Fprintf(outStream, "Warning: found %d of %d instances of container %v.\n",
+			len(instances), c.Instances, containerID)
+	}
+
+	// the instances not restarted yet and the replacements of the restarted ones
+	var known = instances
+
+	for n, uid := range instances {
+		fmt.Fprintf(outStream, "Restarting instance %v of container %v (%d/%d)\n",
+			uid, containerID, n+1, len(instances))
+
+		if err = containers.RestartInstance(projectID, containerID, uid); err != nil {
+			return errwrap.Wrapf("Rolling restart aborted: {{err}}", err)
+		}
+
+		var replacement string
+		replacement, err = WaitRestarted(projectID, containerID, uid, known, timeout)
+
+		if err != nil {
+			return errwrap.Wrapf("Rolling restart aborted: {{err}}", err)
+		}
+
+		known = append(known, replacement)
+	}
+
+	return nil
+}
+
+// restartContainer restarts a container and waits for its instances to be replaced
+func restartContainer(projectID, containerID string, timeout time.Duration) error {
+	var before, err = Instances(projectID, containerID)
+
+	if err != nil {
+		return err
+	}
+
+	if err = containers.Restart(projectID, containerID); err != nil {
+		return err
+	}
+
+	return WaitContainerRestarted(projectID, containerID, before, timeout)
+}
+
+// Sequential restarts containers one at a time (rolling or not),
+// waiting for each one to be restarted and up and aborting on the first failure
+func Sequential(projectID string, containersIDs []string, rolling bool, timeout time.Duration) []workerpool.Result {
+	var results = []workerpool.Result{}
+
+	for _, id := range containersIDs {
+		var start = time.Now()
+		var err error
+
+		if rolling {
+			err = Rolling(projectID, id, timeout)
+		} else {
+			err = restartContainer(projectID, id, timeout)
+		}
+
+		results = append(results, workerpool.Result{
+			Name:     id,
+			Duration: time.Since(start),
+			Err:      err,
+		})
+
+		if err != nil {
+			break
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,345 @@
+package restart
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wedeploy/cli/configmock"
+	"github.com/wedeploy/cli/containers"
+	"github.com/wedeploy/cli/servertest"
+	"github.com/wedeploy/cli/workerpool"
+)
+
+var bufOutStream bytes.Buffer
+
+func TestMain(m *testing.M) {
+	var defaultOutStream = outStream
+	var defaultCheckInterval = checkInterval
+	outStream = &bufOutStream
+	checkInterval = time.Millisecond
+	ec := m.Run()
+	outStream = defaultOutStream
+	checkInterval = defaultCheckInterval
+	os.Exit(ec)
+}
+
+// restartingContainers mocks containers whose restarted instances
+// are replaced by new instances that are down for a few checks
+type restartingContainers struct {
+	t          *testing.T
+	instances  map[string][]containers.Instance
+	restarted  []string
+	downChecks int
+	replace    bool
+	down       map[string]int
+	m          sync.Mutex
+}
+
+func setupRestartingContainers(t *testing.T, downChecks int, replace bool) *restartingContainers {
+	var rc = &restartingContainers{
+		t: t,
+		instances: map[string][]containers.Instance{
+			"bar": []containers.Instance{
+				containers.Instance{UID: "bar_b", Health: "up"},
+				containers.Instance{UID: "bar_a", Health: "up"},
+			},
+			"db":  []containers.Instance{containers.Instance{UID: "db_a", Health: "up"}},
+			"api": []containers.Instance{containers.Instance{UID: "api_a", Health: "up"}},
+			"web": []containers.Instance{containers.Instance{UID: "web_a", Health: "up"}},
+		},
+		downChecks: downChecks,
+		replace:    replace,
+		down:       map[string]int{},
+	}
+
+	servertest.Mux.HandleFunc("/restart/container", rc.restart)
+	servertest.Mux.HandleFunc("/projects/foo/containers/", rc.get)
+	return rc
+}
+
+func (rc *restartingContainers) restart(w http.ResponseWriter, r *http.Request) {
+	rc.m.Lock()
+	defer rc.m.Unlock()
+
+	var container = r.URL.Query().Get("containerId")
+	var uid = r.URL.Query().Get("containerUid")
+
+	for _, i := range rc.instances[container] {
+		if i.Health != "up" {
+			rc.t.Errorf("Expected previous instance to be up before restarting another")
+		}
+	}
+
+	if uid == "" {
+		rc.restarted = append(rc.restarted, container)
+	} else {
+		rc.restarted = append(rc.restarted, uid)
+	}
+
+	if !rc.replace {
+		return
+	}
+
+	var instances = []containers.Instance{}
+
+	for _, i := range rc.instances[container] {
+		if uid != "" && i.UID != uid {
+			instances = append(instances, i)
+			continue
+		}
+
+		var replacement = i.UID + "_new"
+		rc.down[replacement] = rc.downChecks
+		instances = append(instances, containers.Instance{
+			UID: replacement,
+		})
+	}
+
+	rc.instances[container] = instances
+}
+
+func (rc *restartingContainers) get(w http.ResponseWriter, r *http.Request) {
+	rc.m.Lock()
+	defer rc.m.Unlock()
+
+	var container = strings.TrimPrefix(r.URL.Path, "/projects/foo/containers/")
+	w.Header().Set("Content-type", "application/json; charset=UTF-8")
+
+	if !strings.HasSuffix(container, "/instances") {
+		fmt.Fprintf(w, `{"id": "%v", "instances": %d}`, container, len(rc.instances[container]))
+		return
+	}
+
+	var instances = rc.instances[strings.TrimSuffix(container, "/instances")]
+
+	for n, i := range instances {
+		var left, ok = rc.down[i.UID]
+
+		switch {
+		case !ok:
+		case left <= 0 && rc.downChecks >= 0:
+			instances[n].Health = "up"
+		default:
+			instances[n].Health = "down"
+			rc.down[i.UID] = left - 1
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(instances); err != nil {
+		panic(err)
+	}
+}
+
+func (rc *restartingContainers) getRestarted() []string {
+	rc.m.Lock()
+	defer rc.m.Unlock()
+	return rc.restarted
+}
+
+func TestInstances(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	servertest.Mux.HandleFunc("/projects/foo/containers/bar/instances",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-type", "application/json; charset=UTF-8")
+			fmt.Fprintf(w, `[
+	{"containerUid": "bar_b", "health": "up"},
+	{"containerUid": "bar_a", "health": "up"},
+	{"containerUid": "bar_b", "health": "up"}
+]`)
+		})
+
+	var instances, err = Instances("foo", "bar")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = []string{"bar_a", "bar_b"}
+
+	if !reflect.DeepEqual(instances, want) {
+		t.Errorf("Wanted instances %v, got %v instead", want, instances)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestRolling(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+	bufOutStream.Reset()
+
+	// the other instance is up while each restarted instance is replaced
+	var rc = setupRestartingContainers(t, 3, true)
+
+	if err := Rolling("foo", "bar", time.Second); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = []string{"bar_a", "bar_b"}
+
+	if !reflect.DeepEqual(rc.getRestarted(), want) {
+		t.Errorf("Wanted instances restarted in order %v, got %v instead", want, rc.getRestarted())
+	}
+
+	var wantOut = "Restarting instance bar_a of container bar (1/2)\n" +
+		"Restarting instance bar_b of container bar (2/2)\n"
+
+	if bufOutStream.String() != wantOut {
+		t.Errorf("Wanted output %v, got %v instead", wantOut, bufOutStream.String())
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestRollingAbortNotRestarted(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+	bufOutStream.Reset()
+
+	// the restarted instance is never replaced
+	var rc = setupRestartingContainers(t, 0, false)
+
+	var err = Rolling("foo", "bar", 10*time.Millisecond)
+	var wantErr = "Rolling restart aborted: Instance bar_a of container bar didn't restart after 10ms"
+
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("Wanted error %v, got %v instead", wantErr, err)
+	}
+
+	if !reflect.DeepEqual(rc.getRestarted(), []string{"bar_a"}) {
+		t.Errorf("Expected only first instance to be restarted, got %v instead", rc.getRestarted())
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestRollingAbortNotUp(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+	bufOutStream.Reset()
+
+	// the new instance is never up
+	var rc = setupRestartingContainers(t, -1, true)
+
+	var err = Rolling("foo", "bar", 10*time.Millisecond)
+	var wantErr = "Rolling restart aborted: Instance bar_a of container bar is not up after 10ms"
+
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("Wanted error %v, got %v instead", wantErr, err)
+	}
+
+	if !reflect.DeepEqual(rc.getRestarted(), []string{"bar_a"}) {
+		t.Errorf("Expected only first instance to be restarted, got %v instead", rc.getRestarted())
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestSequential(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	var rc = setupRestartingContainers(t, 2, true)
+	var results = Sequential("foo", []string{"db", "api", "web"}, false, time.Second)
+
+	if !reflect.DeepEqual(rc.getRestarted(), []string{"db", "api", "web"}) {
+		t.Errorf("Expected all containers to be restarted, got %v instead", rc.getRestarted())
+	}
+
+	if len(results) != 3 || workerpool.Err(results) != nil {
+		t.Errorf("Expected all containers to be restarted, got %v instead", results)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestSequentialAbortNotRestarted(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	// the container is still up as its instances were not replaced yet
+	var rc = setupRestartingContainers(t, 0, false)
+	var results = Sequential("foo", []string{"db", "api", "web"}, false, 10*time.Millisecond)
+
+	if !reflect.DeepEqual(rc.getRestarted(), []string{"db"}) {
+		t.Errorf("Expected restart to stop after db, got %v instead", rc.getRestarted())
+	}
+
+	var wantErr = "Container db didn't restart after 10ms"
+
+	if len(results) != 1 || results[0].Err == nil || results[0].Err.Error() != wantErr {
+		t.Errorf("Wanted db to fail with %v, got %v instead", wantErr, results)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestSequentialAbortNotUp(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	var rc = setupRestartingContainers(t, -1, true)
+	var results = Sequential("foo", []string{"db", "api", "web"}, false, 10*time.Millisecond)
+
+	if !reflect.DeepEqual(rc.getRestarted(), []string{"db"}) {
+		t.Errorf("Expected restart to stop after db, got %v instead", rc.getRestarted())
+	}
+
+	var wantErr = "Container db is not up after 10ms"
+
+	if len(results) != 1 || results[0].Err == nil || results[0].Err.Error() != wantErr {
+		t.Errorf("Wanted db to fail with %v, got %v instead", wantErr, results)
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
+
+func TestIsRestarted(t *testing.T) {
+	servertest.Setup()
+	configmock.Setup()
+
+	var rc = setupRestartingContainers(t, 1, true)
+	var instances, err = Instances("foo", "db")
+
+	if err != nil {
+		panic(err)
+	}
+
+	if err = containers.Restart("foo", "db"); err != nil {
+		panic(err)
+	}
+
+	var restarted bool
+
+	if restarted, err = IsRestarted("foo", "db", instances); err != nil || restarted {
+		t.Errorf("Expected new instance to be down, got %v (error: %v) instead", restarted, err)
+	}
+
+	if restarted, err = IsRestarted("foo", "db", instances); err != nil || !restarted {
+		t.Errorf("Expected container to be restarted, got %v (error: %v) instead", restarted, err)
+	}
+
+	if !reflect.DeepEqual(rc.getRestarted(), []string{"db"}) {
+		t.Errorf("Expected db to be restarted, got %v instead", rc.getRestarted())
+	}
+
+	configmock.Teardown()
+	servertest.Teardown()
+}
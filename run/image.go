@@ -0,0 +1,323 @@
+package run
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/verbose"
+)
+
+// ImageInfo for a local infrastructure docker image
+type ImageInfo struct {
+	Name    string
+	ID      string
+	Current bool
+}
+
+type archiveManifest struct {
+	RepoTags []string `json:"RepoTags"`
+}
+
+// ErrImageArchiveNoManifest is used when a file is not a docker save archive
+var ErrImageArchiveNoManifest = errors.New("Can't find manifest.json: file is not a docker image archive")
+
+// LoadImage loads the infrastructure image from a "docker save" archive
+func LoadImage(archive string) error {
+	if err := checkDockerExists(); err != nil {
+		return err
+	}
+
+	if err := verifyImageArchive(archive); err != nil {
+		return err
+	}
+
+	fmt.Println("Loading WeDeploy infrastructure docker image from " + archive + ".")
+	return loadImage(archive)
+}
+
+// SaveImage saves the infrastructure image to a "docker save" archive
+func SaveImage(archive string) error {
+	if err := checkDockerExists(); err != nil {
+		return err
+	}
+
+	var dm = &DockerMachine{}
+	dm.checkImage()
+
+	if dm.Image == "" {
+		return errors.New("Docker image " + WeDeployImage + " not found. Run \"we run\" or \"we image load\" first.")
+	}
+
+	verbose.Debug("Running docker save --output " + archive + " " + WeDeployImage)
+	var docker = exec.Command(bin, "save", "--output", archive, WeDeployImage)
+	docker.Stderr = os.Stderr
+	docker.Stdout = os.Stdout
+
+	if err := docker.Run(); err != nil {
+		return errwrap.Wrapf("docker save error: {{err}}", err)
+	}
+
+	fmt.Println("Docker image " + WeDeployImage + " saved to " + archive + ".")
+	return nil
+}
+
+// ListImages lists the infrastructure images available locally
+func ListImages() ([]ImageInfo, error) {
+	if err := checkDockerExists(); err != nil {
+		return nil, err
+	}
+
+	var args = []string{
+		"images",
+		"--format",
+		"{{.Repository}}:{{.Tag}} {{.ID}}",
+		imageRepository,
+	}
+
+	var docker = exec.Command(bin, args...)
+	var buf bytes.Buffer
+	docker.Stderr = os.Stderr
+	docker.Stdout = &buf
+
+	if err := docker.Run(); err != nil {
+		return nil, errwrap.Wrapf("docker images error: {{err}}", err)
+	}
+
+	return parseImagesList(buf.String()), nil
+}
+
+// PruneImages removes the infrastructure images not used by this version
+// nor by any container, such as an outdated named local infrastructure
+func PruneImages() error {
+	var list, err = ListImages()
+
+	if err != nil {
+		return err
+	}
+
+	var images []string
+	images, err = getPrunableImages(list, isImageUsed)
+
+	if err != nil {
+		return err
+	}
+
+	if len(images) == 0 {
+		fmt.Println("No outdated WeDeploy infrastructure images found.")
+		return nil
+	}
+
+	var params = append([]string{"rmi"}, images...)
+	verbose.Debug(fmt.Sprintf("Running docker %v", strings.Join(params, " ")))
+	var rmi = exec.Command(bin, params...)
+	rmi.Stderr = os.Stderr
+	rmi.Stdout = os.Stdout
+
+	if err = rmi.Run(); err != nil {
+		return errwrap.Wrapf("docker rmi error: {{err}}", err)
+	}
+
+	return nil
+}
+
+// getPrunableImages gets the outdated images not used by any container
+func getPrunableImages(list []ImageInfo, used func(image string) (bool, error)) ([]string, error) {
+	var images = []string{}
+
+	for _, i := range list {
+		if i.Current {
+			continue
+		}
+
+		var image = i.Name
+
+		// untagged (dangling) images can only be removed by ID
+		if strings.HasSuffix(i.Name, ":<none>") {
+			image = i.ID
+		}
+
+		var u, err = used(image)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if u {
+			fmt.Println("Skipping image " + image + ": it is used by a container.")
+			continue
+		}
+
+		images = append(images, image)
+	}
+
+	return images, nil
+}
+
+func isImageUsed(image string) (bool, error) {
+	var params = []string{
+		"ps", "--all", "--filter", "ancestor=" + image, "--quiet",
+	}
+
+	verbose.Debug(fmt.Sprintf("Running docker %v", strings.Join(params, " ")))
+	var docker = exec.Command(bin, params...)
+	var buf bytes.Buffer
+	docker.Stderr = os.Stderr
+	docker.Stdout = &buf
+
+	if err := docker.Run(); err != nil {
+		return false, errwrap.Wrapf("docker ps error: {{err}}", err)
+	}
+
+	return strings.TrimSpace(buf.String()) != "", nil
+}
+
+func parseImagesList(list string) []ImageInfo {
+	var images = []ImageInfo{}
+
+	for _, line := range strings.Split(list, "\n") {
+		var fields = strings.Fields(line)
+
+		if len(fields) != 2 {
+			continue
+		}
+
+		images = append(images, ImageInfo{
+			Name:    fields[0],
+			ID:      fields[1],
+			Current: fields[0] == WeDeployImage,
+		})
+	}
+
+	return images
+}
+
+func loadImage(archive string) error {
+	verbose.Debug("Running docker load --input " + archive)
+	var docker = exec.Command(bin, "load", "--input", archive)
+	var buf bytes.Buffer
+	docker.Stderr = os.Stderr
+	docker.Stdout = &buf
+
+	if err := docker.Run(); err != nil {
+		return errwrap.Wrapf("docker load error: {{err}}", err)
+	}
+
+	verbose.Debug(buf.String())
+	return checkLoadedImage(buf.String())
+}
+
+func checkLoadedImage(out string) error {
+	var scanner = bufio.NewScanner(strings.NewReader(out))
+
+	for scanner.Scan() {
+		var line = strings.TrimSpace(scanner.Text())
+
+		if strings.TrimPrefix(line, "Loaded image: ") == WeDeployImage {
+			return nil
+		}
+	}
+
+	return errors.New("Docker image " + WeDeployImage + " was not loaded from the archive.")
+}
+
+func verifyImageArchive(archive string) error {
+	var tags, err = readImageArchiveTags(archive)
+
+	if err != nil {
+		return errwrap.Wrapf("Can't read image archive "+archive+": {{err}}", err)
+	}
+
+	for _, tag := range tags {
+		if tag == WeDeployImage {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Image archive %v contains %v instead of required %v",
+		archive, strings.Join(tags, ", "), WeDeployImage)
+}
+
+func readImageArchiveTags(archive string) ([]string, error) {
+	var file, err = os.Open(archive)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if ec := file.Close(); ec != nil {
+			verbose.Debug("Error closing image archive:", ec)
+		}
+	}()
+
+	var r io.Reader
+	r, err = maybeGzipReader(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return readArchiveManifestTags(tar.NewReader(r))
+}
+
+func maybeGzipReader(file *os.File) (io.Reader, error) {
+	var br = bufio.NewReader(file)
+	var magic, err = br.Peek(2)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+
+	return br, nil
+}
+
+func readArchiveManifestTags(tr *tar.Reader) ([]string, error) {
+	for {
+		var header, err = tr.Next()
+
+		if err == io.EOF {
+			return nil, ErrImageArchiveNoManifest
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Name != "manifest.json" {
+			continue
+		}
+
+		var manifests []archiveManifest
+
+		if err = json.NewDecoder(tr).Decode(&manifests); err != nil {
+			return nil, errwrap.Wrapf("Can't decode manifest.json: {{err}}", err)
+		}
+
+		var tags []string
+
+		for _, m := range manifests {
+			tags = append(tags, m.RepoTags...)
+		}
+
+		return tags, nil
+	}
+}
+
+func isLocallyLoadedImage(image string) bool {
+	// images from "docker load" have no registry digest
+	return dockerInspect("image", "{{len .RepoDigests}}", image) == "0"
+}
@@ -0,0 +1,184 @@
+package run
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseImagesList(t *testing.T) {
+	var list = WeDeployImage + " abc123\n" +
+		imageRepository + ":old def456\n" +
+		imageRepository + ":<none> 789ghi\n" +
+		"\n"
+
+	var want = []ImageInfo{
+		ImageInfo{Name: WeDeployImage, ID: "abc123", Current: true},
+		ImageInfo{Name: imageRepository + ":old", ID: "def456"},
+		ImageInfo{Name: imageRepository + ":<none>", ID: "789ghi"},
+	}
+
+	if got := parseImagesList(list); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted images %v, got %v instead", want, got)
+	}
+}
+
+func TestCheckLoadedImage(t *testing.T) {
+	if err := checkLoadedImage("Loaded image: " + WeDeployImage + "\n"); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if err := checkLoadedImage("Loaded image ID: sha256:abc\n"); err == nil {
+		t.Errorf("Expected error for archive without the infrastructure image")
+	}
+}
+
+func TestReadImageArchiveTags(t *testing.T) {
+	var archive = createImageArchive(false, `[{"RepoTags":["`+WeDeployImage+`"]}]`)
+	defer removeFile(archive)
+
+	var tags, err = readImageArchiveTags(archive)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if !reflect.DeepEqual(tags, []string{WeDeployImage}) {
+		t.Errorf("Expected tags to be %v, got %v instead", WeDeployImage, tags)
+	}
+
+	if err = verifyImageArchive(archive); err != nil {
+		t.Errorf("Expected archive to be valid, got %v instead", err)
+	}
+}
+
+func TestReadImageArchiveTagsGzip(t *testing.T) {
+	var archive = createImageArchive(true, `[{"RepoTags":["foo:1"]},{"RepoTags":["bar:2"]}]`)
+	defer removeFile(archive)
+
+	var tags, err = readImageArchiveTags(archive)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if !reflect.DeepEqual(tags, []string{"foo:1", "bar:2"}) {
+		t.Errorf("Expected tags to be foo:1 and bar:2, got %v instead", tags)
+	}
+
+	var ev = verifyImageArchive(archive)
+
+	if ev == nil || !strings.Contains(ev.Error(), "contains foo:1, bar:2 instead of required") {
+		t.Errorf("Expected tag mismatch error, got %v instead", ev)
+	}
+}
+
+func TestReadImageArchiveTagsNoManifest(t *testing.T) {
+	var archive = createImageArchive(false, "")
+	defer removeFile(archive)
+
+	if _, err := readImageArchiveTags(archive); err != ErrImageArchiveNoManifest {
+		t.Errorf("Expected error to be %v, got %v instead", ErrImageArchiveNoManifest, err)
+	}
+}
+
+func createImageArchive(compress bool, manifest string) string {
+	var file, err = ioutil.TempFile(os.TempDir(), "we-image")
+
+	if err != nil {
+		panic(err)
+	}
+
+	var w io.Writer = file
+	var gw *gzip.Writer
+
+	if compress {
+		gw = gzip.NewWriter(file)
+		w = gw
+	}
+
+	var tw = tar.NewWriter(w)
+
+	addTarFile(tw, "repositories", "{}")
+
+	if manifest != "" {
+		addTarFile(tw, "manifest.json", manifest)
+	}
+
+	if err = tw.Close(); err != nil {
+		panic(err)
+	}
+
+	if gw != nil {
+		if err = gw.Close(); err != nil {
+			panic(err)
+		}
+	}
+
+	if err = file.Close(); err != nil {
+		panic(err)
+	}
+
+	return file.Name()
+}
+
+func addTarFile(tw *tar.Writer, name, content string) {
+	var header = &tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(content)),
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		panic(err)
+	}
+
+	if _, err := tw.Write([]byte(content)); err != nil {
+		panic(err)
+	}
+}
+
+func removeFile(name string) {
+	if err := os.Remove(name); err != nil {
+		panic(err)
+	}
+}
+
+func TestGetPrunableImages(t *testing.T) {
+	var list = []ImageInfo{
+		ImageInfo{Name: WeDeployImage, ID: "abc123", Current: true},
+		ImageInfo{Name: imageRepository + ":old", ID: "def456"},
+		ImageInfo{Name: imageRepository + ":older", ID: "fed654"},
+		ImageInfo{Name: imageRepository + ":<none>", ID: "789ghi"},
+	}
+
+	var checked = []string{}
+
+	var used = func(image string) (bool, error) {
+		checked = append(checked, image)
+		return image == imageRepository+":old", nil
+	}
+
+	var images, err = getPrunableImages(list, used)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = []string{imageRepository + ":older", "789ghi"}
+
+	if !reflect.DeepEqual(images, want) {
+		t.Errorf("Wanted images %v, got %v instead", want, images)
+	}
+
+	var wantChecked = []string{imageRepository + ":old", imageRepository + ":older", "789ghi"}
+
+	if !reflect.DeepEqual(checked, wantChecked) {
+		t.Errorf("Wanted images %v to be checked, got %v instead", wantChecked, checked)
+	}
+}
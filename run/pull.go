@@ -0,0 +1,354 @@
+package run
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/defaults"
+	"github.com/wedeploy/cli/user"
+	"github.com/wedeploy/cli/verbose"
+)
+
+// pullMessage is a docker engine API progress message
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	Error          string `json:"error"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+}
+
+type layerProgress struct {
+	Status  string
+	Current int64
+	Total   int64
+}
+
+type pullProgress struct {
+	layers map[string]*layerProgress
+	order  []string
+	start  time.Time
+	now    func() time.Time
+}
+
+var errDockerAPIUnavailable = errors.New("docker engine API socket not available")
+
+func newPullProgress() *pullProgress {
+	return &pullProgress{
+		layers: map[string]*layerProgress{},
+		start:  time.Now(),
+		now:    time.Now,
+	}
+}
+
+func (p *pullProgress) update(m pullMessage) {
+	// messages without an ID or "Pulling from" are about the image, not a layer
+	if m.ID == "" || strings.HasPrefix(m.Status, "Pulling from") {
+		return
+	}
+
+	var l, ok = p.layers[m.ID]
+
+	if !ok {
+		l = &layerProgress{}
+		p.layers[m.ID] = l
+		p.order = append(p.order, m.ID)
+	}
+
+	l.Status = m.Status
+
+	switch m.Status {
+	case "Downloading":
+		l.Current = m.ProgressDetail.Current
+		l.Total = m.ProgressDetail.Total
+	case "Download complete", "Verifying Checksum", "Extracting",
+		"Pull complete", "Already exists":
+		// download is over, so count the whole layer
+		l.Current = l.Total
+	}
+}
+
+func (p *pullProgress) bytes() (current, total int64) {
+	for _, l := range p.layers {
+		current += l.Current
+		total += l.Total
+	}
+
+	return current, total
+}
+
+func (p *pullProgress) eta() (time.Duration, bool) {
+	var current, total = p.bytes()
+	var elapsed = p.now().Sub(p.start)
+
+	if current == 0 || total == 0 || elapsed <= 0 {
+		return 0, false
+	}
+
+	var remaining = time.Duration(float64(elapsed) * float64(total-current) / float64(current))
+	return remaining - remaining%time.Second, true
+}
+
+func (p *pullProgress) render(w io.Writer) {
+	for _, id := range p.order {
+		var l = p.layers[id]
+		fmt.Fprintf(w, "%v: %v", id, l.Status)
+
+		if l.Total != 0 {
+			fmt.Fprintf(w, " %v/%v", formatBytes(l.Current), formatBytes(l.Total))
+		}
+
+		fmt.Fprintf(w, "\n")
+	}
+
+	var current, total = p.bytes()
+
+	if total == 0 {
+		fmt.Fprintf(w, "Pulling %v...\n", WeDeployImage)
+		return
+	}
+
+	fmt.Fprintf(w, "Pulling %v %d%% (%v/%v)",
+		WeDeployImage,
+		current*100/total,
+		formatBytes(current),
+		formatBytes(total))
+
+	if eta, ok := p.eta(); ok && current != total {
+		fmt.Fprintf(w, " ETA %v", eta)
+	}
+
+	fmt.Fprintf(w, "\n")
+}
+
+func formatBytes(b int64) string {
+	const unit = 1024
+
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+
+	var div, exp = int64(unit), 0
+
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%cB", float64(b)/float64(div), "KMGT"[exp])
+}
+
+func decodePullMessages(r io.Reader, handle func(pullMessage)) error {
+	var decoder = json.NewDecoder(r)
+
+	for {
+		var m pullMessage
+
+		switch err := decoder.Decode(&m); {
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return errwrap.Wrapf("Can't decode docker pull progress: {{err}}", err)
+		case m.Error != "":
+			return errors.New(m.Error)
+		}
+
+		handle(m)
+	}
+}
+
+// dockerConfig has the credentials fields of the docker config.json
+type dockerConfig struct {
+	Auths       map[string]json.RawMessage `json:"auths"`
+	CredsStore  string                     `json:"credsStore"`
+	CredHelpers map[string]string          `json:"credHelpers"`
+}
+
+// dockerHubRegistries are the keys docker uses for Docker Hub credentials
+var dockerHubRegistries = []string{
+	"https://index.docker.io/v1/",
+	"index.docker.io",
+	"docker.io",
+	"registry-1.docker.io",
+}
+
+var errDockerAPINeedsAuth = errors.New("docker pull might need credentials from the docker config")
+
+func (dm *DockerMachine) pull() error {
+	fmt.Println("Pulling WeDeploy infrastructure docker image. Hold on.")
+
+	var err = dm.pullWithProgress()
+
+	if err == errDockerAPINeedsAuth {
+		verbose.Debug("Using docker pull to use the docker credentials:", err)
+		return pullFeedback(pullCmd())
+	}
+
+	if err == errDockerAPIUnavailable {
+		verbose.Debug("Falling back to docker pull without progress:", err)
+		return pullFeedback(pullCmd())
+	}
+
+	return pullFeedback(err)
+}
+
+func (dm *DockerMachine) pullWithProgress() error {
+	var socket, err = getPullSocket(getDockerSocket(), getDockerConfigPath())
+
+	if err != nil {
+		return err
+	}
+
+	var client = &http.Client{
+		Transport: &http.Transport{
+			Dial: func(network, addr string) (net.Conn, error) {
+				return net.Dial("unix", socket)
+			},
+		},
+	}
+
+	var params = url.Values{}
+	params.Set("fromImage", imageRepository)
+	params.Set("tag", defaults.WeDeployImageTag)
+
+	verbose.Debug("Pulling " + WeDeployImage + " using the docker engine API on " + socket)
+	var res *http.Response
+	res, err = client.Post("http://docker/images/create?"+params.Encode(), "text/plain", nil)
+
+	if err != nil {
+		verbose.Debug(err)
+		return errDockerAPIUnavailable
+	}
+
+	defer func() {
+		if ec := res.Body.Close(); ec != nil {
+			verbose.Debug("Error closing docker pull response:", ec)
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("docker engine API responded with %v", res.Status)
+	}
+
+	var progress = newPullProgress()
+
+	err = decodePullMessages(res.Body, func(m pullMessage) {
+		progress.update(m)
+		progress.render(dm.livew)
+
+		if ef := dm.livew.Flush(); ef != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", ef)
+		}
+	})
+
+	return err
+}
+
+// getPullSocket gets the docker engine API socket to pull the image anonymously
+// as the API doesn't use the docker credentials store, docker pull is used
+// whenever the docker config might have credentials for the image registry
+func getPullSocket(socket, configPath string) (string, error) {
+	if socket == "" {
+		return "", errDockerAPIUnavailable
+	}
+
+	var content, err = ioutil.ReadFile(configPath)
+
+	switch {
+	case os.IsNotExist(err):
+		return socket, nil
+	case err != nil:
+		verbose.Debug("Can't read docker config:", err)
+		return "", errDockerAPINeedsAuth
+	}
+
+	var c dockerConfig
+
+	if err = json.Unmarshal(content, &c); err != nil {
+		verbose.Debug("Can't decode docker config:", err)
+		return "", errDockerAPINeedsAuth
+	}
+
+	if c.hasRegistryAuth() {
+		return "", errDockerAPINeedsAuth
+	}
+
+	return socket, nil
+}
+
+func (c dockerConfig) hasRegistryAuth() bool {
+	// a global credentials store might have credentials for any registry
+	if c.CredsStore != "" {
+		return true
+	}
+
+	for _, r := range dockerHubRegistries {
+		if _, ok := c.Auths[r]; ok {
+			return true
+		}
+
+		if _, ok := c.CredHelpers[r]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getDockerConfigPath() string {
+	var dir = os.Getenv("DOCKER_CONFIG")
+
+	if dir == "" {
+		dir = filepath.Join(user.GetHomeDir(), ".docker")
+	}
+
+	return filepath.Join(dir, "config.json")
+}
+
+func getDockerSocket() string {
+	var host = os.Getenv("DOCKER_HOST")
+
+	switch {
+	case host == "":
+		return defaultDockerSocket
+	case strings.HasPrefix(host, "unix://"):
+		return strings.TrimPrefix(host, "unix://")
+	default:
+		return ""
+	}
+}
+
+func pullCmd() error {
+	var docker = exec.Command(bin, "pull", WeDeployImage)
+	docker.Stderr = os.Stderr
+	docker.Stdout = os.Stdout
+	return docker.Run()
+}
+
+func pullFeedback(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// we ignore it for, say, "latest"
+	if defaults.WeDeployImageTag != dockerLatestImageTag {
+		return errwrap.Wrapf("docker pull error: {{err}}\n"+
+			"Can't continue running with an outdated image", err)
+	}
+
+	return errwrap.Wrapf("docker pull error: {{err}}", err)
+}
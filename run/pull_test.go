@@ -0,0 +1,191 @@
+package run
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var pullStream = `{"status":"Pulling from wedeploy/local","id":"latest"}
+{"status":"Pulling fs layer","progressDetail":{},"id":"a1"}
+{"status":"Already exists","progressDetail":{},"id":"b2"}
+{"status":"Downloading","progressDetail":{"current":512,"total":2048},"id":"a1"}
+{"status":"Pulling fs layer","progressDetail":{},"id":"c3"}
+{"status":"Downloading","progressDetail":{"current":1024,"total":2048},"id":"c3"}
+`
+
+func TestPullProgress(t *testing.T) {
+	var p = newPullProgress()
+	var now = p.start.Add(3 * time.Second)
+	p.now = func() time.Time {
+		return now
+	}
+
+	if err := decodePullMessages(strings.NewReader(pullStream), p.update); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var current, total = p.bytes()
+
+	if current != 1536 || total != 4096 {
+		t.Errorf("Expected 1536/4096 bytes, got %v/%v instead", current, total)
+	}
+
+	var eta, ok = p.eta()
+
+	if !ok || eta != 5*time.Second {
+		t.Errorf("Expected ETA to be 5s, got %v instead", eta)
+	}
+
+	var b bytes.Buffer
+	p.render(&b)
+
+	var want = `a1: Downloading 512B/2.0KB
+b2: Already exists
+c3: Downloading 1.0KB/2.0KB
+Pulling ` + WeDeployImage + ` 37% (1.5KB/4.0KB) ETA 5s
+`
+
+	if b.String() != want {
+		t.Errorf("Wanted progress to be %v, got %v instead", want, b.String())
+	}
+}
+
+func TestPullProgressComplete(t *testing.T) {
+	var p = newPullProgress()
+
+	p.update(pullMessage{ID: "a1", Status: "Downloading"})
+	p.layers["a1"].Total = 100
+	p.update(pullMessage{ID: "a1", Status: "Pull complete"})
+
+	var current, total = p.bytes()
+
+	if current != 100 || total != 100 {
+		t.Errorf("Expected layer to be fully downloaded, got %v/%v instead", current, total)
+	}
+}
+
+func TestPullProgressEmpty(t *testing.T) {
+	var p = newPullProgress()
+	var b bytes.Buffer
+
+	p.render(&b)
+
+	if b.String() != "Pulling "+WeDeployImage+"...\n" {
+		t.Errorf("Unexpected progress output %v", b.String())
+	}
+
+	if _, ok := p.eta(); ok {
+		t.Errorf("Expected ETA to be unavailable")
+	}
+}
+
+func TestDecodePullMessagesError(t *testing.T) {
+	var stream = `{"status":"Pulling fs layer","id":"a1"}
+{"error":"manifest unknown"}
+`
+
+	var err = decodePullMessages(strings.NewReader(stream), func(m pullMessage) {})
+
+	if err == nil || err.Error() != "manifest unknown" {
+		t.Errorf("Expected manifest unknown error, got %v instead", err)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	var cases = map[int64]string{
+		0:                  "0B",
+		1023:               "1023B",
+		1024:               "1.0KB",
+		1536:               "1.5KB",
+		5 * 1024 * 1024:    "5.0MB",
+		3 << 30:            "3.0GB",
+		1<<40 + 1<<39:      "1.5TB",
+		1024*1024*1024 - 1: "1024.0MB",
+	}
+
+	for b, want := range cases {
+		if got := formatBytes(b); got != want {
+			t.Errorf("Wanted formatBytes(%v) = %v, got %v instead", b, want, got)
+		}
+	}
+}
+
+func TestGetDockerSocket(t *testing.T) {
+	var defaultHost = os.Getenv("DOCKER_HOST")
+
+	if err := os.Setenv("DOCKER_HOST", "unix:///tmp/docker.sock"); err != nil {
+		panic(err)
+	}
+
+	if got := getDockerSocket(); got != "/tmp/docker.sock" {
+		t.Errorf("Expected socket to be /tmp/docker.sock, got %v instead", got)
+	}
+
+	if err := os.Setenv("DOCKER_HOST", "tcp://127.0.0.1:2375"); err != nil {
+		panic(err)
+	}
+
+	if got := getDockerSocket(); got != "" {
+		t.Errorf("Expected no socket for TCP docker host, got %v instead", got)
+	}
+
+	if err := os.Setenv("DOCKER_HOST", defaultHost); err != nil {
+		panic(err)
+	}
+}
+
+func TestGetPullSocket(t *testing.T) {
+	var cases = map[string]error{
+		"mocks/docker-config/anonymous/config.json":    nil,
+		"mocks/docker-config/not-found/config.json":    nil,
+		"mocks/docker-config/auths/config.json":        errDockerAPINeedsAuth,
+		"mocks/docker-config/creds-store/config.json":  errDockerAPINeedsAuth,
+		"mocks/docker-config/cred-helpers/config.json": errDockerAPINeedsAuth,
+		"mocks/docker-config/invalid/config.json":      errDockerAPINeedsAuth,
+	}
+
+	for config, want := range cases {
+		var socket, err = getPullSocket("/tmp/docker.sock", config)
+
+		if err != want {
+			t.Errorf("Wanted error %v for %v, got %v instead", want, config, err)
+		}
+
+		if want == nil && socket != "/tmp/docker.sock" {
+			t.Errorf("Expected socket to be /tmp/docker.sock for %v, got %v instead", config, socket)
+		}
+
+		if want != nil && socket != "" {
+			t.Errorf("Expected no socket for %v, got %v instead", config, socket)
+		}
+	}
+}
+
+func TestGetPullSocketUnavailable(t *testing.T) {
+	// such as on Windows, where there is no default unix socket
+	var _, err = getPullSocket("", "mocks/docker-config/anonymous/config.json")
+
+	if err != errDockerAPIUnavailable {
+		t.Errorf("Wanted error %v, got %v instead", errDockerAPIUnavailable, err)
+	}
+}
+
+func TestGetDockerConfigPath(t *testing.T) {
+	var defaultConfig = os.Getenv("DOCKER_CONFIG")
+
+	if err := os.Setenv("DOCKER_CONFIG", "/tmp/docker"); err != nil {
+		panic(err)
+	}
+
+	if got := getDockerConfigPath(); got != filepath.Join("/tmp/docker", "config.json") {
+		t.Errorf("Expected docker config on /tmp/docker, got %v instead", got)
+	}
+
+	if err := os.Setenv("DOCKER_CONFIG", defaultConfig); err != nil {
+		panic(err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"regexp"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -17,6 +19,7 @@ import (
 	"github.com/hashicorp/errwrap"
 	"github.com/henvic/uilive"
 	"github.com/wedeploy/cli/defaults"
+	"github.com/wedeploy/cli/link"
 	"github.com/wedeploy/cli/projects"
 	"github.com/wedeploy/cli/prompt"
 	"github.com/wedeploy/cli/verbose"
@@ -34,18 +37,41 @@ const (
 var ErrHostNotFound = errors.New("You need to be connected to a network.")
 
 // WeDeployImage is the docker image for the WeDeploy infrastructure
-var WeDeployImage = "wedeploy/local:" + defaults.WeDeployImageTag
+var WeDeployImage = imageRepository + ":" + defaults.WeDeployImageTag
+
+var imageRepository = "wedeploy/local"
 
 var bin = "docker"
 
 var dockerLatestImageTag = "latest"
 
+// localNameLabel is the docker label for named local infrastructures
+var localNameLabel = "com.wedeploy.local.name"
+
+var (
+	memoryRegex  = regexp.MustCompile(`^[0-9]+[bkmgBKMG]?$`)
+	envRegex     = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*=`)
+	volumeRegex  = regexp.MustCompile(`^.+:/[^:]*(:[a-zA-Z,]+)?$`)
+	networkRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	nameRegex    = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+)
+
 // Flags modifiers
 type Flags struct {
-	Detach   bool
-	DryRun   bool
-	ViewMode bool
-	NoUpdate bool
+	Detach       bool
+	DryRun       bool
+	ViewMode     bool
+	NoUpdate     bool
+	Memory       string
+	CPUs         string
+	Env          []string
+	Volumes      []string
+	Network      string
+	ImageArchive string
+	Logs         bool
+	Upgrade      bool
+	Name         string
+	PortOffset   int
 }
 
 // DockerMachine for the run command
@@ -61,6 +87,8 @@ type DockerMachine struct {
 	end            chan bool
 	started        chan bool
 	selfStopSignal bool
+	startedAt      time.Time
+	relink         *link.Registry
 }
 
 type tcpPortsStruct []int
@@ -76,6 +104,16 @@ var tcpPorts = tcpPortsStruct{
 	9200,
 }
 
+func (t tcpPortsStruct) shift(offset int) tcpPortsStruct {
+	var shifted = tcpPortsStruct{}
+
+	for _, k := range t {
+		shifted = append(shifted, k+offset)
+	}
+
+	return shifted
+}
+
 func (t tcpPortsStruct) getAvailability() (all bool, notAvailable []int) {
 	all = true
 	for _, k := range t {
@@ -103,10 +141,10 @@ func (t tcpPortsStruct) getAvailability() (all bool, notAvailable []int) {
 	return all, notAvailable
 }
 
-func (t tcpPortsStruct) expose() []string {
+func (t tcpPortsStruct) expose(offset int) []string {
 	var ports []string
 	for _, k := range t {
-		ports = append(ports, "-p", fmt.Sprintf("%v:%v", k, k))
+		ports = append(ports, "-p", fmt.Sprintf("%v:%v", k+offset, k))
 	}
 
 	return ports
@@ -134,6 +172,10 @@ func GetWeDeployHost() (string, error) {
 
 // Run runs the WeDeploy infrastructure
 func Run(flags Flags) error {
+	if err := flags.validate(); err != nil {
+		return err
+	}
+
 	if err := checkDockerExists(); err != nil {
 		return err
 	}
@@ -145,13 +187,18 @@ func Run(flags Flags) error {
 	return dm.Run()
 }
 
-// Stop stops the WeDeploy infrastructure
-func Stop() error {
+// Stop stops the WeDeploy infrastructure (name is empty for the default one)
+func Stop(name string) error {
 	if err := checkDockerExists(); err != nil {
 		return err
 	}
 
-	var dm = &DockerMachine{}
+	var dm = &DockerMachine{
+		Flags: Flags{
+			Name: name,
+		},
+	}
+
 	return dm.Stop()
 }
 
@@ -177,6 +224,7 @@ func StopOutdatedImage(nextImage string) error {
 
 	println("New WeDeloy infrastructure image available.")
 	println("The infrastructure must be stopped before updating the CLI tool.")
+	println("You can also keep it running and use \"we run --upgrade\" after updating to keep your linked projects.")
 
 	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
 		return errors.New("No terminal (/dev/tty) detected for asking to stop the infrastructure. Exiting.")
@@ -188,11 +236,16 @@ func StopOutdatedImage(nextImage string) error {
 
 	var q = prompt.Prompt("Stop WeDeploy to allow update [yes]")
 
+	if (q == "n" || q == "no") && nextImage != dockerLatestImageTag {
+		println("Run \"we run --upgrade\" after updating to upgrade the infrastructure.")
+		return nil
+	}
+
 	if q != "" && q != "y" && q != "yes" {
 		return errors.New("Can't update image while running an old version of the infrastructure.")
 	}
 
-	return cleanupEnvironment()
+	return dm.cleanupEnvironment()
 }
 
 // Run executes the WeDeploy infraestruture
@@ -207,11 +260,17 @@ func (dm *DockerMachine) Run() (err error) {
 		return errors.New("View mode is not available: WeDeploy is shutdown.")
 	}
 
+	if dm.Flags.Upgrade && len(dm.Container) != 0 && !dm.Flags.DryRun {
+		if err = dm.upgrade(); err != nil {
+			return err
+		}
+	}
+
 	var already = len(dm.Container) != 0 && !dm.Flags.DryRun
 
 	if already {
 		fmt.Println("WeDeploy is already running.")
-	} else if err = cleanupEnvironment(); err != nil {
+	} else if err = dm.cleanupEnvironment(); err != nil {
 		return err
 	}
 
@@ -225,7 +284,15 @@ func (dm *DockerMachine) Run() (err error) {
 
 	dm.maybeWaitEnd()
 	dm.started <- true
-	go dm.waitReadyState()
+
+	// when running on background the containers must be linked again
+	// before exiting, so wait for the ready state synchronously
+	if dm.Flags.Detach && dm.relink != nil {
+		dm.waitReadyState()
+	} else {
+		go dm.waitReadyState()
+	}
+
 	<-dm.end
 	return nil
 }
@@ -238,7 +305,7 @@ func (dm *DockerMachine) Stop() error {
 		verbose.Debug("No infrastructure container detected.")
 	}
 
-	if err := cleanupEnvironment(); err != nil {
+	if err := dm.cleanupEnvironment(); err != nil {
 		return err
 	}
 
@@ -254,7 +321,7 @@ func (dm *DockerMachine) Stop() error {
 }
 
 func (dm *DockerMachine) checkPortsAreAvailable() error {
-	var all, notAvailable = tcpPorts.getAvailability()
+	var all, notAvailable = tcpPorts.shift(dm.Flags.PortOffset).getAvailability()
 
 	if all {
 		return nil
@@ -333,9 +400,13 @@ func (dm *DockerMachine) waitCleanup() {
 }
 
 func (dm *DockerMachine) start() (err error) {
-	var args = getRunCommandEnv()
+	var args = getRunCommandEnv(dm.Flags)
 	var running = "docker " + strings.Join(args, " ")
 
+	if dm.Flags.DryRun && dm.Flags.ImageArchive != "" {
+		println("docker load --input " + dm.Flags.ImageArchive)
+	}
+
 	if dm.Flags.DryRun && !verbose.Enabled {
 		println(running)
 	} else {
@@ -350,12 +421,12 @@ func (dm *DockerMachine) start() (err error) {
 		return err
 	}
 
-	if !dm.Flags.NoUpdate && !dm.hasCurrentWeDeployImage() {
-		if err := pull(); err != nil {
-			return err
-		}
+	if err = dm.maybeUpdateImage(); err != nil {
+		return err
 	}
 
+	dm.startedAt = time.Now()
+
 	if dm.Container, err = startCmd(args...); err != nil {
 		return err
 	}
@@ -364,13 +435,34 @@ func (dm *DockerMachine) start() (err error) {
 	return err
 }
 
+func (dm *DockerMachine) maybeUpdateImage() error {
+	if dm.Flags.ImageArchive != "" {
+		return LoadImage(dm.Flags.ImageArchive)
+	}
+
+	if dm.Flags.NoUpdate || dm.hasCurrentWeDeployImage() {
+		return nil
+	}
+
+	return dm.pull()
+}
+
 func (dm *DockerMachine) hasCurrentWeDeployImage() bool {
-	if defaults.WeDeployImageTag == dockerLatestImageTag {
-		verbose.Debug("Shortcutting WeDeploy docker image as outdated (because its tag is \"latest\").")
+	if dm.Image != WeDeployImage {
 		return false
 	}
 
-	return dm.Image == WeDeployImage
+	if defaults.WeDeployImageTag != dockerLatestImageTag {
+		return true
+	}
+
+	if isLocallyLoadedImage(WeDeployImage) {
+		verbose.Debug("Using WeDeploy docker image loaded from an archive.")
+		return true
+	}
+
+	verbose.Debug("Shortcutting WeDeploy docker image as outdated (because its tag is \"latest\").")
+	return false
 }
 
 func (dm *DockerMachine) maybeStopListener() {
@@ -395,7 +487,7 @@ func (dm *DockerMachine) stopEvent(sigs chan os.Signal) {
 	dm.selfStopSignal = true
 	fmt.Println("\nStopping WeDeploy.")
 
-	if err := cleanupEnvironment(); err != nil {
+	if err := dm.cleanupEnvironment(); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 	}
 
@@ -453,6 +545,37 @@ func (dm *DockerMachine) ready() {
 	}
 
 	fmt.Println("")
+
+	if dm.relink != nil {
+		dm.relinkAll()
+	}
+
+	if dm.Flags.Logs {
+		go dm.tailLogs()
+	}
+}
+
+func (dm *DockerMachine) tailLogs() {
+	var params = []string{"logs", "--follow"}
+
+	switch dm.startedAt.IsZero() {
+	case true:
+		params = append(params, "--tail", "50")
+	default:
+		params = append(params, "--since", fmt.Sprintf("%d", dm.startedAt.Unix()))
+	}
+
+	params = append(params, dm.Container)
+
+	verbose.Debug(fmt.Sprintf("Running docker %v", strings.Join(params, " ")))
+	var logs = exec.Command(bin, params...)
+	logs.Stderr = os.Stderr
+	logs.Stdout = os.Stdout
+
+	// docker logs ends with an error when the container is removed on shutdown
+	if err := logs.Run(); err != nil {
+		verbose.Debug("docker logs error:", err)
+	}
 }
 
 // LoadDockerInfo loads the docker info on the DockerMachine object
@@ -462,7 +585,7 @@ func (dm *DockerMachine) LoadDockerInfo() {
 		"--filter",
 		"ancestor=" + WeDeployImage,
 		"--format",
-		"{{.ID}} {{.Image}}",
+		psFormat,
 		"--no-trunc",
 	}
 
@@ -476,19 +599,71 @@ func (dm *DockerMachine) LoadDockerInfo() {
 		os.Exit(1)
 	}
 
-	var ps = buf.String()
+	if strings.TrimSpace(buf.String()) == "" {
+		dm.checkImage()
+		return
+	}
 
-	var parts = strings.SplitAfterN(ps, " ", 2)
+	var container, image = findInfrastructure(buf.String(), dm.Flags.Name, WeDeployImage)
 
-	switch len(parts) {
-	case 0:
-		dm.checkImage()
-	case 2:
-		dm.Container = strings.TrimSpace(parts[0])
-		dm.Image = strings.TrimSpace(parts[1])
-	default:
+	if container == "" {
 		verbose.Debug("Running docker not found on docker ps")
+		dm.checkImage()
+		return
+	}
+
+	dm.Container = container
+	dm.Image = image
+}
+
+// psFormat is the docker ps format used to find infrastructure containers
+var psFormat = `{{.ID}} {{.Image}} {{.Label "` + localNameLabel + `"}}`
+
+// findInfrastructure finds the infrastructure container with a given name
+// on a docker ps (psFormat) output, whose image has the given prefix
+func findInfrastructure(ps, name, imagePrefix string) (container, image string) {
+	for _, line := range strings.Split(ps, "\n") {
+		var fields = strings.Fields(line)
+
+		if len(fields) < 2 || !strings.HasPrefix(fields[1], imagePrefix) {
+			continue
+		}
+
+		var label string
+
+		if len(fields) > 2 {
+			label = fields[2]
+		}
+
+		if label == name {
+			return fields[0], fields[1]
+		}
+	}
+
+	return "", ""
+}
+
+// loadOutdatedDockerInfo loads the info of an infrastructure container
+// running an image with a different tag than the current one
+func (dm *DockerMachine) loadOutdatedDockerInfo() {
+	var args = []string{
+		"ps",
+		"--format",
+		psFormat,
+		"--no-trunc",
+	}
+
+	var docker = exec.Command(bin, args...)
+	var buf bytes.Buffer
+	docker.Stderr = os.Stderr
+	docker.Stdout = &buf
+
+	if err := docker.Run(); err != nil {
+		println("docker ps error:", err.Error())
+		os.Exit(1)
 	}
+
+	dm.Container, dm.Image = findInfrastructure(buf.String(), dm.Flags.Name, imageRepository+":")
 }
 
 func (dm *DockerMachine) checkImage() {
@@ -520,10 +695,15 @@ func (dm *DockerMachine) checkImage() {
 func (dm *DockerMachine) testAlreadyRunning() {
 	dm.LoadDockerInfo()
 
+	if dm.Container == "" && dm.Flags.Upgrade {
+		dm.loadOutdatedDockerInfo()
+	}
+
 	// if the infrastructure is already running, test version
-	if dm.Container != "" && WeDeployImage != dm.Image {
+	if dm.Container != "" && WeDeployImage != dm.Image && !dm.Flags.Upgrade {
 		fmt.Fprintf(os.Stderr, "docker image %v found instead of required %v\n", dm.Image, WeDeployImage)
-		println("Stop the infrastructure on docker before running this command again.")
+		println("Stop the infrastructure on docker before running this command again")
+		println("or run \"we run --upgrade\" to upgrade it keeping your linked projects.")
 		os.Exit(1)
 	}
 
@@ -553,58 +733,108 @@ func getWeDeployHost() string {
 	return address
 }
 
-func getRunCommandEnv() []string {
+func getRunCommandEnv(flags Flags) []string {
 	var address = getWeDeployHost()
 	var args = []string{"run"}
 
 	// fluentd might use either TCP or UDP, hence this special case
-	args = append(args, "-p", "24224:24224/udp")
+	args = append(args, "-p", fmt.Sprintf("%v:24224/udp", 24224+flags.PortOffset))
 
-	args = append(args, tcpPorts.expose()...)
+	args = append(args, tcpPorts.expose(flags.PortOffset)...)
 	args = append(args, []string{
 		"-v",
 		"/var/run/docker.sock:/var/run/docker-host.sock",
 		"--privileged",
 		"-e",
 		"WEDEPLOY_HOST_IP=" + address,
-		"--detach",
-		WeDeployImage,
 	}...)
 
+	if flags.Name != "" {
+		args = append(args, "--label", localNameLabel+"="+flags.Name)
+	}
+
+	args = append(args, flags.extraArgs()...)
+	args = append(args, "--detach", WeDeployImage)
+
 	return args
 }
 
-func getDockerPath() string {
-	var path, err = exec.LookPath(bin)
+func (f Flags) extraArgs() []string {
+	var args []string
 
-	if err != nil {
-		panic(err)
+	if f.Memory != "" {
+		args = append(args, "--memory", f.Memory)
 	}
 
-	return path
-}
+	if f.CPUs != "" {
+		args = append(args, "--cpus", f.CPUs)
+	}
 
-func pull() error {
-	fmt.Println("Pulling WeDeploy infrastructure docker image. Hold on.")
-	var docker = exec.Command(bin, "pull", WeDeployImage)
-	docker.Stderr = os.Stderr
-	docker.Stdout = os.Stdout
+	for _, e := range f.Env {
+		args = append(args, "-e", e)
+	}
+
+	for _, v := range f.Volumes {
+		args = append(args, "-v", v)
+	}
 
-	return pullFeedback(docker.Run())
+	if f.Network != "" {
+		args = append(args, "--network", f.Network)
+	}
+
+	return args
 }
 
-func pullFeedback(err error) error {
-	if err == nil {
-		return nil
+func (f Flags) validate() error {
+	if f.Memory != "" && !memoryRegex.MatchString(f.Memory) {
+		return fmt.Errorf("Invalid memory limit \"%v\": use a number optionally followed by b, k, m or g.", f.Memory)
 	}
 
-	// we ignore it for, say, "latest"
-	if defaults.WeDeployImageTag != dockerLatestImageTag {
-		return errwrap.Wrapf("docker pull error: {{err}}\n"+
-			"Can't continue running with an outdated image", err)
+	if f.CPUs != "" {
+		if cpus, err := strconv.ParseFloat(f.CPUs, 64); err != nil || cpus <= 0 {
+			return fmt.Errorf("Invalid number of CPUs \"%v\": use a positive number such as 1.5.", f.CPUs)
+		}
+	}
+
+	for _, e := range f.Env {
+		if !envRegex.MatchString(e) {
+			return fmt.Errorf("Invalid environment variable \"%v\": use the KEY=value format.", e)
+		}
+	}
+
+	for _, v := range f.Volumes {
+		if !volumeRegex.MatchString(v) {
+			return fmt.Errorf("Invalid volume \"%v\": use the source:destination[:options] format.", v)
+		}
 	}
 
-	return errwrap.Wrapf("docker pull error: {{err}}", err)
+	if f.Network != "" && !networkRegex.MatchString(f.Network) {
+		return fmt.Errorf("Invalid network name \"%v\".", f.Network)
+	}
+
+	if f.Name != "" && !nameRegex.MatchString(f.Name) {
+		return fmt.Errorf("Invalid local infrastructure name \"%v\": use lowercase letters, numbers, - and _.", f.Name)
+	}
+
+	if f.Name == "" && f.PortOffset != 0 {
+		return errors.New("Port offset is only available for named local infrastructures.")
+	}
+
+	if f.Logs && f.Detach {
+		return errors.New("Can't show the infrastructure logs when running in background.")
+	}
+
+	return nil
+}
+
+func getDockerPath() string {
+	var path, err = exec.LookPath(bin)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return path
 }
 
 func startCmd(args ...string) (dockerContainer string, err error) {
@@ -621,24 +851,34 @@ func startCmd(args ...string) (dockerContainer string, err error) {
 	return strings.TrimSpace(dockerContainerBuf.String()), err
 }
 
-func cleanupEnvironment() error {
+func (dm *DockerMachine) cleanupEnvironment() error {
+	return cleanupEnvironment(dm.Flags.Name)
+}
+
+func cleanupEnvironment(name string) error {
 	verbose.Debug("Cleaning up processes and containers.")
 
-	if err := stopContainers(); err != nil {
+	if err := stopContainers(name); err != nil {
 		return err
 	}
 
-	if err := rmContainers(); err != nil {
+	if err := rmContainers(name); err != nil {
 		return err
 	}
 
+	if name != "" {
+		if err := forgetTrackedContainers(name); err != nil {
+			return err
+		}
+	}
+
 	verbose.Debug("End of environment clean up.")
 	return nil
 }
 
-func stopContainers() error {
+func stopContainers(name string) error {
 	verbose.Debug("Trying to stop WeDeploy containers and infrastructure containers.")
-	var ids, err = getDockerContainers(true)
+	var ids, err = getDockerContainers(name, true)
 
 	if err != nil {
 		return err
@@ -664,8 +904,8 @@ func stopContainers() error {
 	}
 }
 
-func rmContainers() error {
-	var ids, err = getDockerContainers(false)
+func rmContainers(name string) error {
+	var ids, err = getDockerContainers(name, false)
 
 	if err != nil {
 		return err
@@ -688,20 +928,84 @@ func rmContainers() error {
 	return err
 }
 
-func getDockerContainers(onlyRunning bool) (cids []string, err error) {
-	cids, err = getContainersByLabel("com.wedeploy.container.type", onlyRunning)
+// getDockerContainers gets the containers of a local infrastructure
+// the infrastructure container of a named local infrastructure is found by its label
+// and the containers it started by the IDs tracked for it (see TrackContainers)
+func getDockerContainers(name string, onlyRunning bool) (cids []string, err error) {
+	tracked, err := readTrackedContainers()
 
 	if err != nil {
 		return []string{}, err
 	}
 
-	idsInfra, err := getContainersByLabel("com.wedeploy.project.infra", onlyRunning)
+	apps, err := getContainersByLabel(containerTypeLabel, onlyRunning)
 
 	if err != nil {
 		return []string{}, err
 	}
 
-	return append(cids, idsInfra...), err
+	if name != "" {
+		cids, err = getContainersByLabel(localNameLabel+"="+name, onlyRunning)
+
+		if err != nil {
+			return []string{}, err
+		}
+
+		return append(selectContainers(apps, tracked[name]), cids...), nil
+	}
+
+	named, err := getContainersByLabel(localNameLabel, onlyRunning)
+
+	if err != nil {
+		return []string{}, err
+	}
+
+	cids, err = getContainersByLabel("com.wedeploy.project.infra", onlyRunning)
+
+	if err != nil {
+		return []string{}, err
+	}
+
+	cids = excludeContainers(cids, named)
+	apps = excludeContainers(apps, tracked.all())
+	return append(apps, cids...), nil
+}
+
+// selectContainers keeps only the containers on a given list
+func selectContainers(cids, include []string) []string {
+	var included = map[string]bool{}
+	var list = []string{}
+
+	for _, i := range include {
+		included[i] = true
+	}
+
+	for _, c := range cids {
+		if included[c] {
+			list = append(list, c)
+		}
+	}
+
+	return list
+}
+
+// excludeContainers removes the containers on a given list
+// i.e., so the default infrastructure doesn't stop the named ones
+func excludeContainers(cids, exclude []string) []string {
+	var excluded = map[string]bool{}
+	var list = []string{}
+
+	for _, e := range exclude {
+		excluded[e] = true
+	}
+
+	for _, c := range cids {
+		if !excluded[c] {
+			list = append(list, c)
+		}
+	}
+
+	return list
 }
 
 func getContainersByLabel(label string, onlyRunning bool) (cs []string, err error) {
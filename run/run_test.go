@@ -39,13 +39,82 @@ func TestTCPPortsExpose(t *testing.T) {
 		"-p", "9000:9000",
 	}
 
-	if !reflect.DeepEqual(tcpPorts.expose(), de) {
+	if !reflect.DeepEqual(tcpPorts.expose(0), de) {
 		t.Errorf("Expected ports exposure doesn't match expected value")
 	}
 
 	tcpPorts = originalTCPPorts
 }
 
+func TestTCPPortsExposeOffset(t *testing.T) {
+	var ports = tcpPortsStruct{80, 8000}
+	var de = []string{
+		"-p", "180:80",
+		"-p", "8100:8000",
+	}
+
+	if got := ports.expose(100); !reflect.DeepEqual(got, de) {
+		t.Errorf("Wanted ports exposure %v, got %v instead", de, got)
+	}
+}
+
+func TestTCPPortsShift(t *testing.T) {
+	var ports = tcpPortsStruct{80, 8000}
+	var want = tcpPortsStruct{280, 8200}
+
+	if got := ports.shift(200); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted shifted ports %v, got %v instead", want, got)
+	}
+}
+
+var findInfrastructurePs = `abc ` + WeDeployImage + `
+def ` + WeDeployImage + ` staging
+ghi ` + imageRepository + `:old ci
+jkl nginx:latest
+`
+
+func TestFindInfrastructure(t *testing.T) {
+	var cases = []struct {
+		name      string
+		prefix    string
+		container string
+		image     string
+	}{
+		{"", WeDeployImage, "abc", WeDeployImage},
+		{"staging", WeDeployImage, "def", WeDeployImage},
+		{"ci", WeDeployImage, "", ""},
+		{"ci", imageRepository + ":", "ghi", imageRepository + ":old"},
+		{"missing", imageRepository + ":", "", ""},
+	}
+
+	for _, c := range cases {
+		var container, image = findInfrastructure(findInfrastructurePs, c.name, c.prefix)
+
+		if container != c.container || image != c.image {
+			t.Errorf("Wanted infrastructure %v to be %v %v, got %v %v instead",
+				c.name, c.container, c.image, container, image)
+		}
+	}
+}
+
+func TestExcludeContainers(t *testing.T) {
+	var got = excludeContainers([]string{"a", "b", "c"}, []string{"b", "d"})
+	var want = []string{"a", "c"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted containers %v, got %v instead", want, got)
+	}
+}
+
+func TestSelectContainers(t *testing.T) {
+	var got = selectContainers([]string{"a", "b", "c"}, []string{"b", "d"})
+	var want = []string{"b"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted containers %v, got %v instead", want, got)
+	}
+}
+
 func TestTCPPortsAvailableNone(t *testing.T) {
 	var originalTCPPorts = tcpPorts
 	tcpPorts = tcpPortsStruct{}
@@ -132,3 +201,71 @@ func TestTCPPortsAvailableFree(t *testing.T) {
 
 	tcpPorts = originalTCPPorts
 }
+
+func TestFlagsExtraArgs(t *testing.T) {
+	var flags = Flags{
+		Memory:  "2g",
+		CPUs:    "1.5",
+		Env:     []string{"FOO=bar", "EMPTY="},
+		Volumes: []string{"/tmp/data:/data:ro"},
+		Network: "ci",
+	}
+
+	var want = []string{
+		"--memory", "2g",
+		"--cpus", "1.5",
+		"-e", "FOO=bar",
+		"-e", "EMPTY=",
+		"-v", "/tmp/data:/data:ro",
+		"--network", "ci",
+	}
+
+	if got := flags.extraArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted extra args %v, got %v instead", want, got)
+	}
+}
+
+func TestFlagsExtraArgsEmpty(t *testing.T) {
+	if got := (Flags{}).extraArgs(); len(got) != 0 {
+		t.Errorf("Expected no extra args, got %v instead", got)
+	}
+}
+
+type FlagsValidateProvider struct {
+	flags Flags
+	valid bool
+}
+
+var FlagsValidateCases = []FlagsValidateProvider{
+	{Flags{}, true},
+	{Flags{Memory: "512m"}, true},
+	{Flags{Memory: "1024"}, true},
+	{Flags{Memory: "2 GB"}, false},
+	{Flags{Memory: "-1g"}, false},
+	{Flags{CPUs: "0.5"}, true},
+	{Flags{CPUs: "0"}, false},
+	{Flags{CPUs: "two"}, false},
+	{Flags{Env: []string{"FOO=bar", "A_B="}}, true},
+	{Flags{Env: []string{"FOO"}}, false},
+	{Flags{Env: []string{"1FOO=bar"}}, false},
+	{Flags{Volumes: []string{"/tmp:/data"}}, true},
+	{Flags{Volumes: []string{`C:\data:/data:rw`}}, true},
+	{Flags{Volumes: []string{"/tmp"}}, false},
+	{Flags{Volumes: []string{"/tmp:data"}}, false},
+	{Flags{Network: "my-net.1"}, true},
+	{Flags{Network: "my net"}, false},
+	{Flags{Name: "staging", PortOffset: 100}, true},
+	{Flags{Name: "Staging"}, false},
+	{Flags{Name: "-x"}, false},
+	{Flags{PortOffset: 100}, false},
+}
+
+func TestFlagsValidate(t *testing.T) {
+	for _, c := range FlagsValidateCases {
+		var err = c.flags.validate()
+
+		if c.valid != (err == nil) {
+			t.Errorf("Expected validation of %+v to be %v, got %v instead", c.flags, c.valid, err)
+		}
+	}
+}
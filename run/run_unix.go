@@ -7,6 +7,8 @@ import (
 	"syscall"
 )
 
+const defaultDockerSocket = "/var/run/docker.sock"
+
 func runWait(container string) (*os.Process, error) {
 	return os.StartProcess(getDockerPath(),
 		[]string{bin, "wait", container},
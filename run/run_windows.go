@@ -4,6 +4,10 @@ package run
 
 import "os"
 
+// the docker engine API is served on a named pipe on Windows
+// so progress falls back to the docker pull output
+const defaultDockerSocket = ""
+
 func runWait(container string) (*os.Process, error) {
 	return os.StartProcess(getDockerPath(),
 		[]string{bin, "wait", container},
@@ -0,0 +1,171 @@
+package run
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/user"
+	"github.com/wedeploy/cli/verbose"
+)
+
+// containerTypeLabel is the docker label of the containers started by an infrastructure
+var containerTypeLabel = "com.wedeploy.container.type"
+
+// TrackWait is how long to wait for the containers created by an operation
+// that returns before the infrastructure creates them (such as linking quietly)
+var TrackWait = 10 * time.Second
+
+var trackInterval = time.Second
+
+// trackedContainers has the IDs of the containers started by each
+// named local infrastructure: they can't carry its label as the
+// infrastructure starts them, so they are tracked by the CLI instead
+type trackedContainers map[string][]string
+
+// TrackContainers runs an operation against a named local infrastructure
+// (such as linking) and records the containers created meanwhile as its own
+// so that stopping it stops them too (and stopping the default one doesn't)
+// waiting up to a given duration for them to show up
+func TrackContainers(name string, wait time.Duration, op func() error) error {
+	if name == "" {
+		return op()
+	}
+
+	var before, err = getContainersByLabel(containerTypeLabel, false)
+
+	if err != nil {
+		if eo := op(); eo != nil {
+			return eo
+		}
+
+		return err
+	}
+
+	if err = op(); err != nil {
+		// the containers linked before the failure still need to be tracked
+		if et := trackCreatedContainers(name, before, 0); et != nil {
+			verbose.Debug(et)
+		}
+
+		return err
+	}
+
+	return trackCreatedContainers(name, before, wait)
+}
+
+func trackCreatedContainers(name string, before []string, wait time.Duration) error {
+	var created, existing, err = waitCreatedContainers(before, wait)
+
+	if err != nil {
+		return err
+	}
+
+	if len(created) == 0 {
+		return nil
+	}
+
+	verbose.Debug("Tracking containers of local infrastructure "+name+":", created)
+
+	var tracked trackedContainers
+
+	if tracked, err = readTrackedContainers(); err != nil {
+		return err
+	}
+
+	// containers removed meanwhile (i.e., unlinked) are not tracked anymore
+	for n, ids := range tracked {
+		tracked[n] = selectContainers(ids, existing)
+	}
+
+	tracked[name] = append(tracked[name], created...)
+	return tracked.save()
+}
+
+// waitCreatedContainers waits for containers not on the before list to show up
+// the infrastructure might create the containers after an operation returns
+func waitCreatedContainers(before []string, wait time.Duration) (created, existing []string, err error) {
+	var deadline = time.Now().Add(wait)
+
+	for {
+		existing, err = getContainersByLabel(containerTypeLabel, false)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		created = excludeContainers(existing, before)
+
+		if len(created) != 0 || !time.Now().Before(deadline) {
+			return created, existing, nil
+		}
+
+		time.Sleep(trackInterval)
+	}
+}
+
+func getTrackedContainersPath() string {
+	return filepath.Join(user.GetHomeDir(), ".we_local_containers")
+}
+
+func readTrackedContainers() (trackedContainers, error) {
+	var tracked = trackedContainers{}
+	var content, err = ioutil.ReadFile(getTrackedContainersPath())
+
+	switch {
+	case os.IsNotExist(err):
+		return tracked, nil
+	case err != nil:
+		return nil, errwrap.Wrapf("Can't read local infrastructures containers: {{err}}", err)
+	}
+
+	if err = json.Unmarshal(content, &tracked); err != nil {
+		return nil, errwrap.Wrapf("Can't decode local infrastructures containers: {{err}}", err)
+	}
+
+	return tracked, nil
+}
+
+func (t trackedContainers) save() error {
+	var bin, err = json.MarshalIndent(t, "", "    ")
+
+	if err == nil {
+		err = ioutil.WriteFile(getTrackedContainersPath(), bin, 0644)
+	}
+
+	if err != nil {
+		return errwrap.Wrapf("Can't save local infrastructures containers: {{err}}", err)
+	}
+
+	return nil
+}
+
+// forgetTrackedContainers forgets the containers of a stopped named local infrastructure
+func forgetTrackedContainers(name string) error {
+	var tracked, err = readTrackedContainers()
+
+	if err != nil {
+		return err
+	}
+
+	if _, ok := tracked[name]; !ok {
+		return nil
+	}
+
+	delete(tracked, name)
+	return tracked.save()
+}
+
+// all gets the containers of every named local infrastructure
+func (t trackedContainers) all() []string {
+	var list = []string{}
+
+	for _, ids := range t {
+		list = append(list, ids...)
+	}
+
+	return list
+}
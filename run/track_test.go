@@ -0,0 +1,74 @@
+package run
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestTrackedContainers(t *testing.T) {
+	var home, err = ioutil.TempDir("", "we-run-track")
+
+	if err != nil {
+		panic(err)
+	}
+
+	var defaultHome = os.Getenv("WEDEPLOY_CUSTOM_HOME")
+
+	if err = os.Setenv("WEDEPLOY_CUSTOM_HOME", home); err != nil {
+		panic(err)
+	}
+
+	var tracked trackedContainers
+
+	if tracked, err = readTrackedContainers(); err != nil || len(tracked) != 0 {
+		t.Errorf("Expected no tracked containers, got %v (error: %v) instead", tracked, err)
+	}
+
+	tracked["staging"] = []string{"a", "b"}
+	tracked["ci"] = []string{"c"}
+
+	if err = tracked.save(); err != nil {
+		t.Errorf("Expected no error saving, got %v instead", err)
+	}
+
+	if err = forgetTrackedContainers("staging"); err != nil {
+		t.Errorf("Expected no error forgetting, got %v instead", err)
+	}
+
+	if tracked, err = readTrackedContainers(); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = trackedContainers{
+		"ci": []string{"c"},
+	}
+
+	if !reflect.DeepEqual(tracked, want) {
+		t.Errorf("Wanted tracked containers %v, got %v instead", want, tracked)
+	}
+
+	if err = os.Setenv("WEDEPLOY_CUSTOM_HOME", defaultHome); err != nil {
+		panic(err)
+	}
+
+	if err = os.RemoveAll(home); err != nil {
+		panic(err)
+	}
+}
+
+func TestTrackedContainersAll(t *testing.T) {
+	var tracked = trackedContainers{
+		"staging": []string{"a", "b"},
+		"ci":      []string{"c"},
+	}
+
+	var all = tracked.all()
+	sort.Strings(all)
+
+	if !reflect.DeepEqual(all, []string{"a", "b", "c"}) {
+		t.Errorf("Expected all tracked containers, got %v instead", all)
+	}
+}
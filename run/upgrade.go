@@ -0,0 +1,188 @@
+package run
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/link"
+	"github.com/wedeploy/cli/projects"
+	"github.com/wedeploy/cli/user"
+	"github.com/wedeploy/cli/verbose"
+)
+
+// UpgradeBackup is the exported state of the infrastructure before an upgrade
+type UpgradeBackup struct {
+	Image    string               `json:"image"`
+	Projects []projects.Project   `json:"projects"`
+	Links    []link.RegistryEntry `json:"links"`
+}
+
+// upgrade replaces an outdated infrastructure container keeping
+// the list of linked containers to link them again when it is ready
+func (dm *DockerMachine) upgrade() error {
+	if err := dm.pull(); err != nil {
+		return err
+	}
+
+	// the image is already up to date now, so don't try to update it again
+	dm.Flags.NoUpdate = true
+
+	if !dm.isOutdated() {
+		fmt.Println("WeDeploy infrastructure is up to date.")
+		return nil
+	}
+
+	fmt.Println("Upgrading WeDeploy infrastructure to " + WeDeployImage + ".")
+
+	var registry, err = link.GetRegistry()
+
+	if err != nil {
+		return err
+	}
+
+	if err = dm.exportProjects(registry); err != nil {
+		return err
+	}
+
+	if err = dm.cleanupEnvironment(); err != nil {
+		return err
+	}
+
+	dm.Container = ""
+	dm.Image = WeDeployImage
+	dm.relink = registry
+	return nil
+}
+
+func (dm *DockerMachine) isOutdated() bool {
+	var running = dockerInspect("container", "{{.Image}}", dm.Container)
+	var current = dockerInspect("image", "{{.Id}}", WeDeployImage)
+
+	verbose.Debug("Running infrastructure image " + running + ", current image " + current)
+	return running == "" || running != current
+}
+
+func (dm *DockerMachine) exportProjects(registry *link.Registry) error {
+	var list, err = projects.List()
+
+	if err != nil {
+		return errwrap.Wrapf("Can't export linked projects: {{err}}", err)
+	}
+
+	var backup = UpgradeBackup{
+		Image:    dm.Image,
+		Projects: list,
+		Links:    registry.Entries,
+	}
+
+	bin, err := json.MarshalIndent(backup, "", "    ")
+
+	if err != nil {
+		return err
+	}
+
+	var path = getUpgradeBackupPath()
+
+	if err = ioutil.WriteFile(path, bin, 0644); err != nil {
+		return errwrap.Wrapf("Can't export linked projects: {{err}}", err)
+	}
+
+	fmt.Println("Linked projects exported to " + path + ".")
+	return nil
+}
+
+func (dm *DockerMachine) relinkAll() {
+	var projectPaths = dm.relink.Projects()
+	var keys = make([]string, 0, len(projectPaths))
+
+	for k := range projectPaths {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, projectPath := range keys {
+		var entries = filterLocalEntries(projectPaths[projectPath], dm.Flags.Name)
+
+		if len(entries) == 0 {
+			continue
+		}
+
+		var err = TrackContainers(dm.Flags.Name, TrackWait, func() error {
+			relinkProject(projectPath, entries)
+			return nil
+		})
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	dm.relink = nil
+}
+
+func filterLocalEntries(entries []link.RegistryEntry, local string) []link.RegistryEntry {
+	var filtered = []link.RegistryEntry{}
+
+	for _, e := range entries {
+		if e.Local == local {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
+
+func relinkProject(projectPath string, entries []link.RegistryEntry) {
+	var dirs []string
+
+	for _, e := range entries {
+		var dir, err = filepath.Rel(projectPath, e.ContainerPath)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Can't link %v again: %v\n", e.ContainerPath, err)
+			continue
+		}
+
+		dirs = append(dirs, dir)
+	}
+
+	var m = &link.Machine{
+		FErrStream: os.Stderr,
+	}
+
+	if err := m.Setup(projectPath, dirs); err != nil {
+		fmt.Fprintf(os.Stderr, "Can't link project %v again: %v\n", projectPath, err)
+		return
+	}
+
+	m.Run()
+
+	fmt.Printf("Project %v linked again (%d of %d containers).\n",
+		m.Project.ID, len(m.Linked()), len(entries))
+}
+
+func dockerInspect(inspectType, format, name string) string {
+	var docker = exec.Command(bin, "inspect", "--type", inspectType, "--format", format, name)
+	var buf bytes.Buffer
+	docker.Stdout = &buf
+
+	if err := docker.Run(); err != nil {
+		verbose.Debug("docker inspect error:", err)
+		return ""
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+func getUpgradeBackupPath() string {
+	return filepath.Join(user.GetHomeDir(), ".we_upgrade_backup")
+}
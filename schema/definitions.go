@@ -0,0 +1,146 @@
+package schema
+
+// IDPattern is the format of the projects and containers IDs
+const IDPattern = `^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
+
+// ProjectSchema is the JSON Schema of project.json
+const ProjectSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "title": "project.json",
+    "type": "object",
+    "properties": {
+        "id": {
+            "type": "string",
+            "pattern": "` + IDPattern + `"
+        },
+        "name": {
+            "type": "string"
+        },
+        "customDomain": {
+            "type": "string"
+        },
+        "description": {
+            "type": "string"
+        }
+    },
+    "required": ["id"],
+    "additionalProperties": false
+}`
+
+// ContainerSchema is the JSON Schema of container.json
+const ContainerSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "title": "container.json",
+    "type": "object",
+    "properties": {
+        "id": {
+            "type": "string",
+            "pattern": "` + IDPattern + `"
+        },
+        "name": {
+            "type": "string"
+        },
+        "type": {
+            "type": "string"
+        },
+        "hooks": {
+            "type": "object",
+            "properties": {
+                "before_build": {"type": "string"},
+                "build": {"type": "string"},
+                "after_build": {"type": "string"},
+                "before_deploy": {"type": "string"},
+                "deploy": {"type": "string"},
+                "after_deploy": {"type": "string"},
+                "before_link": {"type": "string"},
+                "after_link": {"type": "string"}
+            },
+            "additionalProperties": false
+        },
+        "env": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "instances": {
+            "type": "integer",
+            "minimum": 1
+        },
+        "dependsOn": {
+            "type": "array",
+            "items": {
+                "type": "string",
+                "pattern": "` + IDPattern + `"
+            }
+        },
+        "tags": {
+            "type": "array",
+            "items": {
+                "type": "string"
+            }
+        }
+    },
+    "required": ["id"],
+    "additionalProperties": false
+}`
+
+// AuthSchema is the JSON Schema of auth.json
+const AuthSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "title": "auth.json",
+    "type": "object",
+    "properties": {
+        "containerAuthenticationProvider": {"type": ["string", "null"]},
+        "hashAlgorithm": {"type": ["string", "null"]},
+        "loginPageUrl": {"type": ["string", "null"]},
+        "loginRedirectPageUrl": {"type": ["string", "null"]},
+        "loginAuthenticationUrl": {"type": ["string", "null"]},
+        "logoutRedirectPageUrl": {"type": ["string", "null"]},
+        "logoutAuthenticationUrl": {"type": ["string", "null"]},
+        "passwordParam": {"type": ["string", "null"]},
+        "permissionsParam": {"type": ["string", "null"]},
+        "realm": {"type": ["string", "null"]},
+        "realmRestPath": {"type": ["string", "null"]},
+        "roles": {"type": "object"},
+        "rolesParam": {"type": ["string", "null"]},
+        "userParam": {"type": ["string", "null"]}
+    },
+    "additionalProperties": false
+}`
+
+// AnswersSchema is the JSON Schema of the answers file of we create --answers
+const AnswersSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "title": "answers",
+    "type": "object",
+    "properties": {
+        "id": {"type": "string"},
+        "type": {"type": "string"},
+        "name": {"type": "string"},
+        "customDomain": {"type": "string"},
+        "env": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "template": {"type": "string"},
+        "offline": {"type": "boolean"}
+    },
+    "additionalProperties": false
+}`
+
+var (
+	// Project schema
+	Project = mustParse(ProjectSchema)
+
+	// Container schema
+	Container = mustParse(ContainerSchema)
+
+	// Auth schema
+	Auth = mustParse(AuthSchema)
+
+	// Answers schema
+	Answers = mustParse(AnswersSchema)
+)
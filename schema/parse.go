@@ -0,0 +1,296 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSON value kinds
+const (
+	kindNull = iota
+	kindBool
+	kindNumber
+	kindString
+	kindArray
+	kindObject
+)
+
+var kindNames = map[int]string{
+	kindNull:   "null",
+	kindBool:   "boolean",
+	kindNumber: "number",
+	kindString: "string",
+	kindArray:  "array",
+	kindObject: "object",
+}
+
+// node of a JSON document, with the line where it starts
+type node struct {
+	kind   int
+	line   int
+	value  interface{}
+	keys   []string
+	fields map[string]*node
+	items  []*node
+}
+
+// parser of JSON documents keeping track of lines,
+// so errors can point to where they are on the file
+type parser struct {
+	data []byte
+	pos  int
+	line int
+}
+
+// parse a JSON document
+func parse(data []byte) (*node, *Error) {
+	var p = &parser{
+		data: data,
+		line: 1,
+	}
+
+	var n, err = p.parseValue()
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+
+	if p.pos != len(p.data) {
+		return nil, p.errorf("Unexpected %q after the end of the document.", string(p.data[p.pos]))
+	}
+
+	return n, nil
+}
+
+func (p *parser) errorf(format string, a ...interface{}) *Error {
+	return &Error{
+		Line:    p.line,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+func (p *parser) skipSpace() {
+	for ; p.pos < len(p.data); p.pos++ {
+		switch p.data[p.pos] {
+		case '\n':
+			p.line++
+		case ' ', '\t', '\r':
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) parseValue() (*node, *Error) {
+	p.skipSpace()
+
+	if p.pos == len(p.data) {
+		return nil, p.errorf("Unexpected end of the document.")
+	}
+
+	switch c := p.data[p.pos]; {
+	case c == '{':
+		return p.parseObject()
+	case c == '[':
+		return p.parseArray()
+	case c == '"':
+		return p.parseString()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	default:
+		return p.parseLiteral()
+	}
+}
+
+func (p *parser) parseObject() (*node, *Error) {
+	var n = &node{
+		kind:   kindObject,
+		line:   p.line,
+		fields: map[string]*node{},
+	}
+
+	p.pos++
+	p.skipSpace()
+
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.pos++
+		return n, nil
+	}
+
+	for {
+		p.skipSpace()
+
+		if p.pos == len(p.data) || p.data[p.pos] != '"' {
+			return nil, p.errorf("Expected a field name.")
+		}
+
+		var key, err = p.parseString()
+
+		if err != nil {
+			return nil, err
+		}
+
+		var k = key.value.(string)
+
+		if _, ok := n.fields[k]; ok {
+			return nil, &Error{
+				Line:    key.line,
+				Message: fmt.Sprintf("Duplicated field %v.", k),
+			}
+		}
+
+		p.skipSpace()
+
+		if p.pos == len(p.data) || p.data[p.pos] != ':' {
+			return nil, p.errorf("Expected ':' after field %v.", k)
+		}
+
+		p.pos++
+
+		value, err := p.parseValue()
+
+		if err != nil {
+			return nil, err
+		}
+
+		n.keys = append(n.keys, k)
+		n.fields[k] = value
+
+		if end, err := p.next('}'); end || err != nil {
+			return n, err
+		}
+	}
+}
+
+func (p *parser) parseArray() (*node, *Error) {
+	var n = &node{
+		kind:  kindArray,
+		line:  p.line,
+		items: []*node{},
+	}
+
+	p.pos++
+	p.skipSpace()
+
+	if p.pos < len(p.data) && p.data[p.pos] == ']' {
+		p.pos++
+		return n, nil
+	}
+
+	for {
+		var item, err = p.parseValue()
+
+		if err != nil {
+			return nil, err
+		}
+
+		n.items = append(n.items, item)
+
+		if end, err := p.next(']'); end || err != nil {
+			return n, err
+		}
+	}
+}
+
+// next consumes the separator between values, telling if the closing char was found
+func (p *parser) next(closing byte) (bool, *Error) {
+	p.skipSpace()
+
+	if p.pos == len(p.data) {
+		return false, p.errorf("Unexpected end of the document.")
+	}
+
+	switch p.data[p.pos] {
+	case ',':
+		p.pos++
+		return false, nil
+	case closing:
+		p.pos++
+		return true, nil
+	default:
+		return false, p.errorf("Expected ',' or '%c', found %q.", closing, string(p.data[p.pos]))
+	}
+}
+
+func (p *parser) parseString() (*node, *Error) {
+	var start = p.pos
+	p.pos++
+
+	for ; p.pos < len(p.data); p.pos++ {
+		switch p.data[p.pos] {
+		case '\\':
+			p.pos++
+		case '\n':
+			return nil, p.errorf("Unexpected line break on string.")
+		case '"':
+			p.pos++
+
+			var s string
+
+			if err := json.Unmarshal(p.data[start:p.pos], &s); err != nil {
+				return nil, p.errorf("Invalid string: %v.", err)
+			}
+
+			return &node{
+				kind:  kindString,
+				line:  p.line,
+				value: s,
+			}, nil
+		}
+	}
+
+	return nil, p.errorf("Unexpected end of the document.")
+}
+
+func (p *parser) parseNumber() (*node, *Error) {
+	var start = p.pos
+
+	for ; p.pos < len(p.data); p.pos++ {
+		var c = p.data[p.pos]
+
+		if !(c >= '0' && c <= '9') && c != '-' && c != '+' && c != '.' && c != 'e' && c != 'E' {
+			break
+		}
+	}
+
+	var raw = p.data[start:p.pos]
+	var f float64
+
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, p.errorf("Invalid number %v.", string(raw))
+	}
+
+	return &node{
+		kind:  kindNumber,
+		line:  p.line,
+		value: f,
+	}, nil
+}
+
+func (p *parser) parseLiteral() (*node, *Error) {
+	var literals = []struct {
+		text  string
+		kind  int
+		value interface{}
+	}{
+		{"true", kindBool, true},
+		{"false", kindBool, false},
+		{"null", kindNull, nil},
+	}
+
+	for _, l := range literals {
+		if len(p.data)-p.pos >= len(l.text) && string(p.data[p.pos:p.pos+len(l.text)]) == l.text {
+			p.pos += len(l.text)
+
+			return &node{
+				kind:  l.kind,
+				line:  p.line,
+				value: l.value,
+			}, nil
+		}
+	}
+
+	return nil, p.errorf("Unexpected %q.", string(p.data[p.pos]))
+}
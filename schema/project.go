@@ -0,0 +1,154 @@
+package schema
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/wedeploy/cli/definition"
+)
+
+// ValidateProject validates the project.json, auth.json and container.json files
+// of a project, checking also that containers IDs are not duplicated
+func ValidateProject(projectPath string) error {
+	var es, err = validateProject(projectPath)
+
+	if err != nil {
+		return err
+	}
+
+	if len(es) != 0 {
+		sortErrors(es)
+		return es
+	}
+
+	return nil
+}
+
+func validateProject(projectPath string) (Errors, error) {
+	var es = Errors{}
+	var pes, _, _, err = validateDefinition(Project, projectPath, "", "project")
+
+	if err != nil {
+		return nil, err
+	}
+
+	es = append(es, pes...)
+
+	aes, _, _, err := validateDefinition(Auth, projectPath, "", "auth")
+
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		es = append(es, aes...)
+	}
+
+	files, err := ioutil.ReadDir(projectPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var ids = map[string]string{}
+
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+
+		var ces, n, file, err = validateDefinition(Container, projectPath, f.Name(), "container")
+
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return nil, err
+		}
+
+		es = append(es, ces...)
+
+		if id, ok := getID(n); ok {
+			if other, dup := ids[id.value.(string)]; dup {
+				es = append(es, Error{
+					File:    file,
+					Line:    id.line,
+					Message: fmt.Sprintf("ID %v is duplicated on %v.", id.value, other),
+				})
+			}
+
+			ids[id.value.(string)] = file
+		}
+	}
+
+	return es, nil
+}
+
+// validateDefinition validates a definition (i.e., container for container.json
+// or container.yaml) of a directory relative to the project path
+// YAML files are validated after converted to JSON, so their errors have no line
+func validateDefinition(s *Schema, projectPath, dir, name string) (es Errors, n *node, file string, err error) {
+	var path string
+	path, err = definition.Find(filepath.Join(projectPath, dir), name)
+
+	if mfe, ok := err.(definition.MultipleFormatsError); ok {
+		file = filepath.Join(dir, name)
+		return Errors{Error{File: file, Message: mfe.Error()}}, nil, file, nil
+	}
+
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	file = filepath.Join(dir, filepath.Base(path))
+	content, err := definition.Read(path)
+
+	if err != nil {
+		return Errors{Error{File: file, Message: err.Error()}}, nil, file, nil
+	}
+
+	n, es = s.validateDocument(file, content)
+
+	if definition.Format(path) == definition.YAML {
+		clearLines(es, n)
+	}
+
+	return es, n, file, nil
+}
+
+func clearLines(es Errors, n *node) {
+	for i := range es {
+		es[i].Line = 0
+	}
+
+	var clear func(n *node)
+
+	clear = func(n *node) {
+		if n == nil {
+			return
+		}
+
+		n.line = 0
+
+		for _, f := range n.fields {
+			clear(f)
+		}
+
+		for _, i := range n.items {
+			clear(i)
+		}
+	}
+
+	clear(n)
+}
+
+func getID(n *node) (*node, bool) {
+	if n == nil || n.kind != kindObject {
+		return nil, false
+	}
+
+	var id, ok = n.fields["id"]
+	return id, ok && id.kind == kindString
+}
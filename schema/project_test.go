@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateProject(t *testing.T) {
+	if err := ValidateProject("mocks/project"); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+}
+
+func TestValidateProjectErrors(t *testing.T) {
+	var err = ValidateProject("mocks/invalid-project")
+
+	var want = Errors{
+		Error{"one/container.json", 3, "Unknown field instace."},
+		Error{"one/container.json", 4, "Field instances must be integer, not string."},
+		Error{"one/container.json", 6, "Unknown field hooks.buld."},
+		Error{"one/container.json", 9, "Field env.PORT must be string, not number."},
+		Error{"project.json", 2, `Field id "Shop" doesn't match the pattern ` + IDPattern + "."},
+		Error{"project.json", 3, "Unknown field custom_domain."},
+		Error{"two/container.json", 2, "ID email is duplicated on one/container.json."},
+		Error{"two/container.json", 3, "Field instances must be integer, not number."},
+	}
+
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("Wanted errors %v, got %v instead", want, err)
+	}
+}
+
+func TestValidateProjectNotFound(t *testing.T) {
+	if err := ValidateProject("mocks/not-found"); err == nil {
+		t.Errorf("Expected error, got nil instead")
+	}
+}
+
+func TestValidateProjectYAML(t *testing.T) {
+	var err = ValidateProject("mocks/yaml-project")
+
+	var want = Errors{
+		Error{"one/container.yaml", 0, "Unknown field instace."},
+	}
+
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("Wanted errors %v, got %v instead", want, err)
+	}
+
+	var wantMsg = "List of errors (format is file:line: error)\none/container.yaml: Unknown field instace."
+
+	if err.Error() != wantMsg {
+		t.Errorf("Wanted error message %v, got %v instead", wantMsg, err)
+	}
+}
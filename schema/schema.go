@@ -0,0 +1,323 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wedeploy/cli/definition"
+)
+
+// Schema for JSON documents
+// it supports a subset of JSON Schema (draft 4) enough for the definition files:
+// type, properties, additionalProperties, required, pattern, items and minimum
+type Schema struct {
+	Title                string             `json:"title,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+
+	pattern      *regexp.Regexp
+	additional   *Schema
+	noAdditional bool
+}
+
+// Types a value might have (a JSON Schema type is either a string or a list)
+type Types []string
+
+// UnmarshalJSON decodes a type or list of types
+func (t *Types) UnmarshalJSON(b []byte) error {
+	var s string
+
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = Types{s}
+		return nil
+	}
+
+	var list []string
+	var err = json.Unmarshal(b, &list)
+	*t = Types(list)
+	return err
+}
+
+// Error of a document not following its schema
+type Error struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e Error) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%v: %v", e.File, e.Message)
+	}
+
+	return fmt.Sprintf("%v:%d: %v", e.File, e.Line, e.Message)
+}
+
+// Errors list
+type Errors []Error
+
+func (es Errors) Error() string {
+	var msgs = []string{}
+
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+
+	return fmt.Sprintf("List of errors (format is file:line: error)\n%v",
+		strings.Join(msgs, "\n"))
+}
+
+// unknownFieldFormat is the message of the errors of unknown fields
+const unknownFieldFormat = "Unknown field %v."
+
+// SplitUnknownFields splits the errors of unknown fields from the other errors
+// (unknown fields might be legacy or extra fields, so they can be taken as warnings)
+func SplitUnknownFields(es Errors) (unknown, other Errors) {
+	var prefix = strings.TrimSuffix(unknownFieldFormat, "%v.")
+
+	for _, e := range es {
+		if strings.HasPrefix(e.Message, prefix) {
+			unknown = append(unknown, e)
+		} else {
+			other = append(other, e)
+		}
+	}
+
+	return unknown, other
+}
+
+// Parse a JSON schema
+func Parse(content string) (*Schema, error) {
+	var s = &Schema{}
+
+	if err := json.Unmarshal([]byte(content), s); err != nil {
+		return nil, err
+	}
+
+	return s, s.compile()
+}
+
+func mustParse(content string) *Schema {
+	var s, err = Parse(content)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func (s *Schema) compile() (err error) {
+	if s.Pattern != "" {
+		if s.pattern, err = regexp.Compile(s.Pattern); err != nil {
+			return err
+		}
+	}
+
+	switch a := strings.TrimSpace(string(s.AdditionalProperties)); a {
+	case "", "true":
+	case "false":
+		s.noAdditional = true
+	default:
+		s.additional = &Schema{}
+
+		if err = json.Unmarshal(s.AdditionalProperties, s.additional); err != nil {
+			return err
+		}
+
+		if err = s.additional.compile(); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range s.Properties {
+		if err = p.compile(); err != nil {
+			return err
+		}
+	}
+
+	if s.Items != nil {
+		return s.Items.compile()
+	}
+
+	return nil
+}
+
+// ValidateFile validates a JSON or YAML file
+// YAML files are validated after converted to JSON, so their errors have no line
+func (s *Schema) ValidateFile(file string) error {
+	var content, err = definition.Read(file)
+
+	if err != nil {
+		return err
+	}
+
+	var es = s.Validate(file, content)
+
+	if definition.Format(file) == definition.YAML {
+		clearLines(es, nil)
+	}
+
+	if len(es) != 0 {
+		return es
+	}
+
+	return nil
+}
+
+// Validate a JSON document, using the file name on the errors
+func (s *Schema) Validate(file string, content []byte) Errors {
+	var _, es = s.validateDocument(file, content)
+	return es
+}
+
+func (s *Schema) validateDocument(file string, content []byte) (*node, Errors) {
+	var n, err = parse(content)
+
+	if err != nil {
+		err.File = file
+		return nil, Errors{*err}
+	}
+
+	var v = &validator{
+		file: file,
+	}
+
+	v.validate(s, "", n)
+	return n, v.errors
+}
+
+type validator struct {
+	file   string
+	errors Errors
+}
+
+func (v *validator) errorf(n *node, format string, a ...interface{}) {
+	v.errors = append(v.errors, Error{
+		File:    v.file,
+		Line:    n.line,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+func (v *validator) validate(s *Schema, path string, n *node) {
+	if !s.hasType(n) {
+		v.errorf(n, "%v must be %v, not %v.",
+			describe(path), strings.Join(s.Type, " or "), kindNames[n.kind])
+		return
+	}
+
+	switch n.kind {
+	case kindObject:
+		v.validateObject(s, path, n)
+	case kindArray:
+		if s.Items != nil {
+			for i, item := range n.items {
+				v.validate(s.Items, fmt.Sprintf("%v[%d]", path, i), item)
+			}
+		}
+	case kindString:
+		if s.pattern != nil && !s.pattern.MatchString(n.value.(string)) {
+			v.errorf(n, "%v %q doesn't match the pattern %v.",
+				describe(path), n.value, s.Pattern)
+		}
+	case kindNumber:
+		if s.Minimum != nil && n.value.(float64) < *s.Minimum {
+			v.errorf(n, "%v must be at least %v.", describe(path), *s.Minimum)
+		}
+	}
+}
+
+func (v *validator) validateObject(s *Schema, path string, n *node) {
+	for _, r := range s.Required {
+		if _, ok := n.fields[r]; !ok {
+			v.errorf(n, "Missing required field %v.", join(path, r))
+		}
+	}
+
+	for _, k := range n.keys {
+		var p, ok = s.Properties[k]
+
+		switch {
+		case ok:
+		case s.additional != nil:
+			p = s.additional
+		case s.noAdditional:
+			v.errorf(n.fields[k], unknownFieldFormat, join(path, k))
+			continue
+		default:
+			continue
+		}
+
+		v.validate(p, join(path, k), n.fields[k])
+	}
+}
+
+func (s *Schema) hasType(n *node) bool {
+	if len(s.Type) == 0 {
+		return true
+	}
+
+	for _, t := range s.Type {
+		switch {
+		case t == kindNames[n.kind]:
+			return true
+		case t == "integer" && n.kind == kindNumber:
+			var f = n.value.(float64)
+
+			if f == math.Trunc(f) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+
+	return path + "." + field
+}
+
+func describe(path string) string {
+	if path == "" {
+		return "Document"
+	}
+
+	return "Field " + path
+}
+
+// sortErrors by file and line
+func sortErrors(es Errors) {
+	sort.Sort(byLocation(es))
+}
+
+type byLocation Errors
+
+func (b byLocation) Len() int {
+	return len(b)
+}
+
+func (b byLocation) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
+
+func (b byLocation) Less(i, j int) bool {
+	if b[i].File != b[j].File {
+		return b[i].File < b[j].File
+	}
+
+	return b[i].Line < b[j].Line
+}
@@ -0,0 +1,154 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateContainer(t *testing.T) {
+	var es = Container.Validate("container.json", []byte(`{
+    "id": "email",
+    "instace": 2,
+    "dependsOn": ["web", 3]
+}`))
+
+	var want = Errors{
+		Error{"container.json", 3, "Unknown field instace."},
+		Error{"container.json", 4, "Field dependsOn[1] must be string, not number."},
+	}
+
+	if !reflect.DeepEqual(es, want) {
+		t.Errorf("Wanted errors %v, got %v instead", want, es)
+	}
+}
+
+func TestValidateMissingRequired(t *testing.T) {
+	var es = Project.Validate("project.json", []byte(`{"name": "Shop"}`))
+
+	var want = Errors{
+		Error{"project.json", 1, "Missing required field id."},
+	}
+
+	if !reflect.DeepEqual(es, want) {
+		t.Errorf("Wanted errors %v, got %v instead", want, es)
+	}
+}
+
+func TestValidateDocumentType(t *testing.T) {
+	var es = Project.Validate("project.json", []byte(`["shop"]`))
+
+	var want = Errors{
+		Error{"project.json", 1, "Document must be object, not array."},
+	}
+
+	if !reflect.DeepEqual(es, want) {
+		t.Errorf("Wanted errors %v, got %v instead", want, es)
+	}
+}
+
+func TestValidateNullableType(t *testing.T) {
+	if es := Auth.Validate("auth.json", []byte(`{"loginPageUrl": null, "realm": "rest"}`)); len(es) != 0 {
+		t.Errorf("Expected no errors, got %v instead", es)
+	}
+
+	var es = Auth.Validate("auth.json", []byte(`{"realm": false}`))
+
+	var want = Errors{
+		Error{"auth.json", 1, "Field realm must be string or null, not boolean."},
+	}
+
+	if !reflect.DeepEqual(es, want) {
+		t.Errorf("Wanted errors %v, got %v instead", want, es)
+	}
+}
+
+func TestValidateSyntaxError(t *testing.T) {
+	var err = Container.ValidateFile("mocks/corrupted.json")
+
+	var want = Errors{
+		Error{"mocks/corrupted.json", 4, `Expected ',' or '}', found "\"".`},
+	}
+
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("Wanted error %v, got %v instead", want, err)
+	}
+}
+
+func TestParseDuplicatedField(t *testing.T) {
+	var _, err = parse([]byte("{\n\"id\": \"a\",\n\"id\": \"b\"\n}"))
+
+	if err == nil || err.Line != 3 || err.Message != "Duplicated field id." {
+		t.Errorf("Expected duplicated field error, got %v instead", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	var n, err = parse([]byte(`{"a": [1, -2.5e3, true, false, null, "x\"y"], "b": {}}`))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+
+	var items = n.fields["a"].items
+	var want = []interface{}{1.0, -2500.0, true, false, nil, `x"y`}
+
+	for i, item := range items {
+		if item.value != want[i] {
+			t.Errorf("Wanted item %d to be %v, got %v instead", i, want[i], item.value)
+		}
+	}
+
+	if !reflect.DeepEqual(n.keys, []string{"a", "b"}) {
+		t.Errorf("Expected keys in order, got %v instead", n.keys)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var cases = []string{
+		``,
+		`{`,
+		`{"a" 1}`,
+		`{"a": tru}`,
+		`[1,]`,
+		`{"a": 1} x`,
+		`"abc`,
+		`{1: 2}`,
+	}
+
+	for _, c := range cases {
+		if _, err := parse([]byte(c)); err == nil {
+			t.Errorf("Expected error parsing %q, got nil instead", c)
+		}
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	if _, err := Parse(`{"pattern": "["}`); err == nil {
+		t.Errorf("Expected invalid pattern error, got nil instead")
+	}
+
+	if _, err := Parse(`{"additionalProperties": {"type": 3}}`); err == nil {
+		t.Errorf("Expected invalid type error, got nil instead")
+	}
+}
+
+func TestSplitUnknownFields(t *testing.T) {
+	var es = Errors{
+		Error{"project.json", 3, "Unknown field custom_domain."},
+		Error{"project.json", 2, "Field id must be string, not number."},
+		Error{"one/container.json", 4, "Unknown field hooks.buld."},
+	}
+
+	var unknown, other = SplitUnknownFields(es)
+
+	var wantUnknown = Errors{es[0], es[2]}
+	var wantOther = Errors{es[1]}
+
+	if !reflect.DeepEqual(unknown, wantUnknown) {
+		t.Errorf("Wanted unknown fields %v, got %v instead", wantUnknown, unknown)
+	}
+
+	if !reflect.DeepEqual(other, wantOther) {
+		t.Errorf("Wanted other errors %v, got %v instead", wantOther, other)
+	}
+}
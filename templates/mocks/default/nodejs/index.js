@@ -0,0 +1 @@
+hidden
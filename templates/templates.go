@@ -0,0 +1,226 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wedeploy/cli/config"
+	"github.com/wedeploy/cli/user"
+	"github.com/wedeploy/cli/verbose"
+)
+
+// Template of a container source tree
+type Template struct {
+	Name string
+	Path string
+}
+
+// Data available to the templates
+type Data struct {
+	ID        string
+	Name      string
+	Type      string
+	ProjectID string
+}
+
+// ErrTemplateNotFound happens when a template is not found on the templates directories
+var ErrTemplateNotFound = errors.New("Template not found")
+
+// ignored files are not part of the templates
+var ignored = map[string]bool{
+	".git":      true,
+	".DS_Store": true,
+}
+
+// DefaultDir is the default templates directory
+func DefaultDir() string {
+	return filepath.Join(user.GetHomeDir(), ".we_templates")
+}
+
+// Dirs gets the templates directories configured on ~/.we followed by the default one
+func Dirs() []string {
+	var dirs = []string{}
+
+	if config.Global != nil {
+		dirs = append(dirs, config.Global.TemplatesDirs...)
+	}
+
+	return append(dirs, DefaultDir())
+}
+
+// List the templates of the given directories
+// a template on a directory hides the ones with the same name on the next ones
+func List(dirs []string) ([]Template, error) {
+	var found = map[string]bool{}
+	var list = []Template{}
+
+	for _, dir := range dirs {
+		var files, err = ioutil.ReadDir(dir)
+
+		switch {
+		case os.IsNotExist(err):
+			verbose.Debug("Templates directory " + dir + " not found.")
+			continue
+		case err != nil:
+			return nil, errwrap.Wrapf("Can't list templates: {{err}}", err)
+		}
+
+		for _, f := range files {
+			if !f.IsDir() || ignored[f.Name()] || found[f.Name()] {
+				continue
+			}
+
+			found[f.Name()] = true
+			list = append(list, Template{
+				Name: f.Name(),
+				Path: filepath.Join(dir, f.Name()),
+			})
+		}
+	}
+
+	sort.Sort(byName(list))
+	return list, nil
+}
+
+type byName []Template
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byName) Less(i, j int) bool { return b[i].Name < b[j].Name }
+
+// Find a template by name on the given directories
+func Find(dirs []string, name string) (Template, error) {
+	var list, err = List(dirs)
+
+	if err != nil {
+		return Template{}, err
+	}
+
+	for _, t := range list {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+
+	return Template{}, ErrTemplateNotFound
+}
+
+// IsRepository tells if a template source is a git repository URL
+func IsRepository(source string) bool {
+	return strings.Contains(source, "://") ||
+		strings.HasPrefix(source, "git@") ||
+		strings.HasSuffix(source, ".git")
+}
+
+// Clone a template from a git repository into a temporary directory
+// remove its Path when it is no longer needed
+func Clone(url string) (Template, error) {
+	var dir, err = ioutil.TempDir(os.TempDir(), "we-template")
+
+	if err != nil {
+		return Template{}, err
+	}
+
+	var cmd = exec.Command("git", "clone", "--depth", "1", url, dir)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	verbose.Debug("> git clone --depth 1 " + url + " " + dir)
+
+	if err = cmd.Run(); err != nil {
+		if er := os.RemoveAll(dir); er != nil {
+			verbose.Debug("Error removing template directory: " + er.Error())
+		}
+
+		return Template{}, fmt.Errorf("Can't clone template %v: %v %v",
+			url, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return Template{
+		Name: url,
+		Path: dir,
+	}, nil
+}
+
+// Instantiate the template on a directory
+// the files and their paths are Go templates executed with the data
+func (t Template) Instantiate(dest string, data Data) error {
+	return filepath.Walk(t.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		var rel, errRel = filepath.Rel(t.Path, path)
+
+		if errRel != nil || rel == "." {
+			return errRel
+		}
+
+		if ignored[info.Name()] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if rel, err = execute(rel, data); err != nil {
+			return errwrap.Wrapf("Can't instantiate template path "+path+": {{err}}", err)
+		}
+
+		var target = filepath.Join(dest, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+
+		return instantiateFile(path, target, info, data)
+	})
+}
+
+func instantiateFile(path, target string, info os.FileInfo, data Data) error {
+	var content, err = ioutil.ReadFile(path)
+
+	if err != nil {
+		return err
+	}
+
+	// binary files are copied as they are
+	if !bytes.Contains(content, []byte{0}) {
+		var s string
+
+		if s, err = execute(string(content), data); err != nil {
+			return errwrap.Wrapf("Can't instantiate template file "+path+": {{err}}", err)
+		}
+
+		content = []byte(s)
+	}
+
+	verbose.Debug("Creating " + target)
+	return ioutil.WriteFile(target, content, info.Mode().Perm())
+}
+
+func execute(text string, data Data) (string, error) {
+	var tmpl, err = template.New("").Option("missingkey=error").Parse(text)
+
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+
+	if err = tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
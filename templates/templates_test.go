@@ -0,0 +1,109 @@
+package templates
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wedeploy/cli/tdata"
+)
+
+var dirs = []string{"mocks/user", "mocks/missing", "mocks/default"}
+
+func TestList(t *testing.T) {
+	var list, err = List(dirs)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = []Template{
+		Template{Name: "gradle", Path: filepath.Join("mocks", "user", "gradle")},
+		Template{Name: "nodejs", Path: filepath.Join("mocks", "user", "nodejs")},
+		Template{Name: "ruby", Path: filepath.Join("mocks", "default", "ruby")},
+	}
+
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("Wanted templates %v, got %v instead", want, list)
+	}
+}
+
+func TestFind(t *testing.T) {
+	var tmpl, err = Find(dirs, "ruby")
+
+	if err != nil || tmpl.Path != filepath.Join("mocks", "default", "ruby") {
+		t.Errorf("Expected ruby template to be found, got %v (%v) instead", tmpl, err)
+	}
+
+	if _, err = Find(dirs, "python"); err != ErrTemplateNotFound {
+		t.Errorf("Wanted error %v, got %v instead", ErrTemplateNotFound, err)
+	}
+}
+
+func TestIsRepository(t *testing.T) {
+	var cases = map[string]bool{
+		"https://github.com/wedeploy/templates": true,
+		"git@github.com:wedeploy/templates.git": true,
+		"templates.git":                         true,
+		"nodejs":                                false,
+		"../templates/nodejs":                   false,
+	}
+
+	for source, want := range cases {
+		if got := IsRepository(source); got != want {
+			t.Errorf("Wanted IsRepository(%v) to be %v, got %v instead", source, want, got)
+		}
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	var dest, err = ioutil.TempDir(os.TempDir(), "we-template-test")
+
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dest); err != nil {
+			panic(err)
+		}
+	}()
+
+	var tmpl = Template{
+		Name: "nodejs",
+		Path: "mocks/user/nodejs",
+	}
+
+	err = tmpl.Instantiate(dest, Data{
+		ID:        "api",
+		Name:      "My API",
+		Type:      "nodejs",
+		ProjectID: "shop",
+	})
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var wantPackage = `{
+    "name": "api",
+    "description": "My API on shop"
+}
+`
+
+	if got := tdata.FromFile(filepath.Join(dest, "package.json")); got != wantPackage {
+		t.Errorf("Wanted package.json %v, got %v instead", wantPackage, got)
+	}
+
+	if got := tdata.FromFile(filepath.Join(dest, "src", "api.js")); got != "console.log('nodejs');\n" {
+		t.Errorf("Unexpected src/api.js content %v", got)
+	}
+}
+
+func TestExecuteUnknownField(t *testing.T) {
+	if _, err := execute("{{.Unknown}}", Data{}); err == nil {
+		t.Errorf("Expected error executing template with unknown field")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+
+	"github.com/wedeploy/cli/definition"
 )
 
 // Context structure
@@ -12,6 +14,8 @@ type Context struct {
 	ProjectRoot   string
 	ContainerRoot string
 	Remote        string
+	Local         string
+	Profile       string
 	Endpoint      string
 	Username      string
 	Password      string
@@ -33,7 +37,7 @@ func init() {
 func Get() (*Context, error) {
 	cx := &Context{}
 
-	var project, errProject = getRootDirectory(sysRoot, "project.json")
+	var project, errProject = getRootDirectory(sysRoot, "project.json", "project.yaml")
 
 	cx.ProjectRoot = project
 
@@ -42,7 +46,7 @@ func Get() (*Context, error) {
 		return cx, nil
 	}
 
-	var container, errContainer = getRootDirectory(project, "container.json")
+	var container, errContainer = getRootDirectory(project, "container.json", "container.yaml")
 
 	if errContainer != nil {
 		cx.Scope = "project"
@@ -56,32 +60,39 @@ func Get() (*Context, error) {
 }
 
 func checkContainerNotInProjectRoot(projectRoot string) error {
-	stat, err := os.Stat(filepath.Join(projectRoot, "container.json"))
-
-	if err == nil && !stat.IsDir() {
+	if definition.Exists(projectRoot, "container") {
 		return ErrContainerInProjectRoot
 	}
 
 	return nil
 }
 
-func walkToRootDirectory(dir, delimiter, file string) (string, error) {
+// walkToRootDirectory finds the directory with any of the files
+func walkToRootDirectory(dir, delimiter string, files ...string) (string, error) {
 	// sysRoot = / = upper-bound / The Power of Ten rule 2
 	for !isRootDelimiter(dir) && dir != delimiter {
-		stat, err := os.Stat(filepath.Join(dir, file))
-
-		if stat == nil {
+		if !hasAnyFile(dir, files) {
 			dir = filepath.Join(dir, "..")
 			continue
 		}
 
-		return dir, err
+		return dir, nil
 	}
 
 	return "", os.ErrNotExist
 }
 
-func getRootDirectory(delimiter, file string) (dir string, err error) {
+func hasAnyFile(dir string, files []string) bool {
+	for _, file := range files {
+		if stat, _ := os.Stat(filepath.Join(dir, file)); stat != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getRootDirectory(delimiter string, files ...string) (dir string, err error) {
 	dir, err = os.Getwd()
 
 	if err != nil {
@@ -94,5 +105,5 @@ func getRootDirectory(delimiter, file string) (dir string, err error) {
 		return "", os.ErrNotExist
 	}
 
-	return walkToRootDirectory(dir, delimiter, file)
+	return walkToRootDirectory(dir, delimiter, files...)
 }
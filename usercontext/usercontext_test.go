@@ -231,3 +231,31 @@ func setSysRoot(dir string) {
 		panic(err)
 	}
 }
+
+func TestYAMLContainerContext(t *testing.T) {
+	setSysRoot("./mocks")
+	var projectDir = filepath.Join(workingDir, "mocks/yaml-project")
+	var containerDir = filepath.Join(projectDir, "container")
+	chdir(containerDir)
+
+	var usercontext, err = Get()
+
+	if usercontext.Scope != "container" {
+		t.Errorf("Expected context to be container, got %s instead", usercontext.Scope)
+	}
+
+	if usercontext.ProjectRoot != projectDir {
+		t.Errorf("Wanted projectDir %s, got %s instead", projectDir, usercontext.ProjectRoot)
+	}
+
+	if usercontext.ContainerRoot != containerDir {
+		t.Errorf("Wanted containerDir %s, got %s instead", containerDir, usercontext.ContainerRoot)
+	}
+
+	if err != nil {
+		t.Errorf("Unexpected context error: %v", err)
+	}
+
+	chdir(workingDir)
+	setSysRoot("/")
+}
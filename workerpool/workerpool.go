@@ -0,0 +1,175 @@
+package workerpool
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultParallel is the default number of tasks running at the same time
+var DefaultParallel = 4
+
+// Task to run on the pool
+// Run should return soon after cancel is closed, when the task times out.
+type Task struct {
+	Name string
+	Run  func(cancel <-chan struct{}) error
+}
+
+// Result of a task
+type Result struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// TimeoutError is used when a task takes longer than its timeout
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (te TimeoutError) Error() string {
+	return fmt.Sprintf("Timed out after %v", te.Timeout)
+}
+
+// Errors of the failed tasks
+type Errors []Result
+
+func (e Errors) Error() string {
+	var msgs = []string{}
+
+	for _, r := range e {
+		msgs = append(msgs, fmt.Sprintf("%v: %v", r.Name, r.Err))
+	}
+
+	return fmt.Sprintf("List of errors (format is name: error)\n%v",
+		strings.Join(msgs, "\n"))
+}
+
+// Run the tasks with at most parallel tasks running at the same time
+// (no limit if parallel is zero) and a timeout for each one (no timeout if zero)
+// The results are in the same order of the tasks.
+// A task that times out is canceled and waited for, so it doesn't outlive Run.
+func Run(tasks []Task, parallel int, timeout time.Duration) []Result {
+	var results = make([]Result, len(tasks))
+	var queue = make(chan int)
+	var wg sync.WaitGroup
+
+	if parallel <= 0 || parallel > len(tasks) {
+		parallel = len(tasks)
+	}
+
+	wg.Add(parallel)
+
+	for i := 0; i < parallel; i++ {
+		go func() {
+			for n := range queue {
+				results[n] = runTask(tasks[n], timeout)
+			}
+
+			wg.Done()
+		}()
+	}
+
+	for n := range tasks {
+		queue <- n
+	}
+
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+func runTask(task Task, timeout time.Duration) Result {
+	var start = time.Now()
+	var done = make(chan error, 1)
+	var cancel = make(chan struct{})
+
+	go func() {
+		done <- task.Run(cancel)
+	}()
+
+	var r = Result{
+		Name: task.Name,
+	}
+
+	if timeout == 0 {
+		r.Err = <-done
+		r.Duration = time.Since(start)
+		return r
+	}
+
+	select {
+	case r.Err = <-done:
+	case <-time.After(timeout):
+		close(cancel)
+		<-done
+		r.Err = TimeoutError{
+			Timeout: timeout,
+		}
+	}
+
+	r.Duration = time.Since(start)
+	return r
+}
+
+// Failed gets the failed results
+func Failed(results []Result) []Result {
+	var failed = []Result{}
+
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+
+	return failed
+}
+
+// Err gets the error of the failed tasks, if any
+func Err(results []Result) error {
+	var failed = Failed(results)
+
+	switch {
+	case len(failed) == 0:
+		return nil
+	case len(results) == 1:
+		return failed[0].Err
+	default:
+		return Errors(failed)
+	}
+}
+
+// PrintSummary prints a table with the duration and outcome of each task
+func PrintSummary(w io.Writer, action string, results []Result) {
+	var tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Name\tDuration\tResult\n")
+
+	for _, r := range results {
+		var outcome = action
+
+		if r.Err != nil {
+			outcome = "failed: " + r.Err.Error()
+		}
+
+		fmt.Fprintf(tw, "%v\t%v\t%v\n", r.Name, formatDuration(r.Duration), outcome)
+	}
+
+	if err := tw.Flush(); err != nil {
+		panic(err)
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	var precision = 100 * time.Millisecond
+
+	if d < time.Second {
+		precision = time.Millisecond
+	}
+
+	return (d / precision * precision).String()
+}
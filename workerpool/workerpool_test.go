@@ -0,0 +1,167 @@
+package workerpool
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	var tasks = []Task{}
+
+	for i := 0; i < 5; i++ {
+		var n = i
+		tasks = append(tasks, Task{
+			Name: fmt.Sprintf("task%d", n),
+			Run: func(cancel <-chan struct{}) error {
+				if n == 3 {
+					return errors.New("Failure.")
+				}
+
+				return nil
+			},
+		})
+	}
+
+	var results = Run(tasks, 2, 0)
+
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 results, got %v instead", results)
+	}
+
+	for i, r := range results {
+		if r.Name != tasks[i].Name {
+			t.Errorf("Expected result %d to be for %v, got %v instead", i, tasks[i].Name, r.Name)
+		}
+	}
+
+	var failed = Failed(results)
+
+	if len(failed) != 1 || failed[0].Name != "task3" {
+		t.Errorf("Expected only task3 to fail, got %v instead", failed)
+	}
+}
+
+func TestErr(t *testing.T) {
+	var e = errors.New("Failure.")
+
+	if err := Err([]Result{Result{Name: "a"}}); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if err := Err([]Result{Result{Name: "a", Err: e}}); err != e {
+		t.Errorf("Expected task error, got %v instead", err)
+	}
+
+	var err = Err([]Result{
+		Result{Name: "a", Err: e},
+		Result{Name: "b"},
+		Result{Name: "c", Err: e},
+	})
+
+	var want = "List of errors (format is name: error)\na: Failure.\nc: Failure."
+
+	if err == nil || err.Error() != want {
+		t.Errorf("Wanted error %v, got %v instead", want, err)
+	}
+}
+
+func TestRunParallel(t *testing.T) {
+	var running, max int
+	var m sync.Mutex
+	var tasks = []Task{}
+
+	for i := 0; i < 10; i++ {
+		tasks = append(tasks, Task{
+			Run: func(cancel <-chan struct{}) error {
+				m.Lock()
+				running++
+
+				if running > max {
+					max = running
+				}
+
+				m.Unlock()
+				time.Sleep(5 * time.Millisecond)
+				m.Lock()
+				running--
+				m.Unlock()
+				return nil
+			},
+		})
+	}
+
+	Run(tasks, 3, 0)
+
+	if max > 3 {
+		t.Errorf("Expected at most 3 tasks running at the same time, got %v instead", max)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	var tasks = []Task{
+		Task{
+			Name: "slow",
+			Run: func(cancel <-chan struct{}) error {
+				select {
+				case <-cancel:
+				case <-time.After(time.Second):
+					t.Errorf("Expected slow task to be canceled")
+				}
+
+				return nil
+			},
+		},
+		Task{
+			Name: "fast",
+			Run: func(cancel <-chan struct{}) error {
+				return nil
+			},
+		},
+	}
+
+	var results = Run(tasks, 0, 20*time.Millisecond)
+
+	if _, ok := results[0].Err.(TimeoutError); !ok {
+		t.Errorf("Expected slow task to time out, got %v instead", results[0].Err)
+	}
+
+	if results[1].Err != nil {
+		t.Errorf("Expected fast task to not fail, got %v instead", results[1].Err)
+	}
+
+	if results[0].Err.Error() != "Timed out after 20ms" {
+		t.Errorf("Unexpected timeout message %v", results[0].Err)
+	}
+}
+
+func TestPrintSummary(t *testing.T) {
+	var b bytes.Buffer
+
+	PrintSummary(&b, "linked", []Result{
+		Result{
+			Name:     "db",
+			Duration: 1500 * time.Millisecond,
+		},
+		Result{
+			Name:     "api",
+			Duration: 2 * time.Second,
+			Err:      TimeoutError{Timeout: 2 * time.Second},
+		},
+	})
+
+	var want = strings.Join([]string{
+		"Name  Duration  Result",
+		"db    1.5s      linked",
+		"api   2s        failed: Timed out after 2s",
+		"",
+	}, "\n")
+
+	if b.String() != want {
+		t.Errorf("Wanted summary %v, got %v instead", want, b.String())
+	}
+}